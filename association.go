@@ -0,0 +1,228 @@
+package dumbo
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// An Association fills a foreign key column of generated records with a
+// parent row chosen by its Strategy. Partials that set the column win.
+type Association struct {
+	Column   string
+	Strategy Strategy
+}
+
+// A Strategy chooses the parent row of an association.
+//
+// Reusing strategies pick from rows inserted earlier in the current scope,
+// falling back to rows already in the parent table. A new parent is created
+// when there is nothing to pick from.
+type Strategy interface {
+	pick(r *rand.Rand, candidates []Record) (Record, bool)
+}
+
+type create struct{}
+
+// Create a new parent row for every record.
+func Create() Strategy {
+	return create{}
+}
+
+func (create) pick(*rand.Rand, []Record) (Record, bool) {
+	return nil, false
+}
+
+type reuseRandom struct{}
+
+// Attach every record to a random parent row.
+func ReuseRandom() Strategy {
+	return reuseRandom{}
+}
+
+func (reuseRandom) pick(r *rand.Rand, candidates []Record) (Record, bool) {
+	if len(candidates) == 0 {
+		return nil, false
+	}
+	return candidates[r.Intn(len(candidates))], true
+}
+
+type roundRobin struct {
+	next *int
+}
+
+// Attach records to parent rows in turn.
+func RoundRobin() Strategy {
+	return roundRobin{next: new(int)}
+}
+
+func (s roundRobin) pick(_ *rand.Rand, candidates []Record) (Record, bool) {
+	if len(candidates) == 0 {
+		return nil, false
+	}
+	picked := candidates[*s.next%len(candidates)]
+	*s.next++
+	return picked, true
+}
+
+type weighted struct {
+	weight func(r Record) int
+}
+
+// Attach records to random parent rows in proportion to their weight.
+func Weighted(weight func(r Record) int) Strategy {
+	return weighted{weight: weight}
+}
+
+func (s weighted) pick(r *rand.Rand, candidates []Record) (Record, bool) {
+	if len(candidates) == 0 {
+		return nil, false
+	}
+	weights := make([]int, len(candidates))
+	total := 0
+	for i, candidate := range candidates {
+		if w := s.weight(candidate); w > 0 {
+			weights[i] = w
+			total += w
+		}
+	}
+	if total == 0 {
+		return candidates[r.Intn(len(candidates))], true
+	}
+	n := r.Intn(total)
+	for i, w := range weights {
+		if n < w {
+			return candidates[i], true
+		}
+		n -= w
+	}
+	return candidates[len(candidates)-1], true
+}
+
+// Copy the partials, filling the associations of the factory.
+func (d *Dumbo) associate(t testing.TB, db DB, r *rand.Rand, factory Factory, partials []Record) []Record {
+	t.Helper()
+	if len(factory.Associations) == 0 {
+		return partials
+	}
+
+	described, err := d.catalog.table(db, factory.Table)
+	require.NoError(t, err, fmt.Sprintf("describing table %q", factory.Table))
+
+	associated := make([]Record, len(partials))
+	for i, partial := range partials {
+		associated[i] = make(Record, len(partial))
+		for column, value := range partial {
+			associated[i][column] = value
+		}
+	}
+
+	for _, association := range factory.Associations {
+		fk, ok := described.foreignKey(association.Column)
+		require.True(t, ok, fmt.Sprintf(
+			"column %q of table %q is not a foreign key",
+			association.Column, factory.Table,
+		))
+
+		var candidates []Record
+		if _, isCreate := association.Strategy.(create); !isCreate {
+			candidates = d.candidates(t, db, fk.references, fk.keys)
+		}
+
+		for _, record := range associated {
+			if _, isSet := record[association.Column]; isSet {
+				continue
+			}
+			parent, ok := association.Strategy.pick(r, candidates)
+			if !ok {
+				parent = d.InsertOne(t, db, fk.references, Record{})
+				if _, isCreate := association.Strategy.(create); !isCreate {
+					// later records reuse the parent
+					candidates = append(candidates, parent)
+				}
+			}
+			for i, column := range fk.columns {
+				key, ok := parent[fk.keys[i]]
+				if !ok {
+					require.FailNow(t, fmt.Sprintf(
+						"parent row of table %q has no column %q for foreign key %q, was it inserted with Returning?",
+						fk.references, fk.keys[i], fk.name,
+					))
+				}
+				record[column] = key
+			}
+		}
+	}
+
+	return associated
+}
+
+// Rows of the table inserted in the current scope, or else already in the
+// table in key order.
+func (d *Dumbo) candidates(t testing.TB, db DB, table string, keys []string) []Record {
+	t.Helper()
+	candidates := make([]Record, 0)
+	for _, run := range d.runs {
+		candidates = append(candidates, run.inserted[table]...)
+	}
+	if len(candidates) > 0 {
+		return candidates
+	}
+	return d.FetchMany(t, db, fmt.Sprintf(`select * from %q order by %v`, table, selection(keys)))
+}
+
+// Remember the records inserted into the table for the rest of the test.
+func (d *Dumbo) track(t testing.TB, table string, records []Record) []Record {
+	run := d.runs[len(d.runs)-1]
+	run.inserted[table] = append(run.inserted[table], records...)
+	t.Cleanup(func() {
+		tracked := make(map[uintptr]bool, len(records))
+		for _, record := range records {
+			tracked[reflect.ValueOf(record).Pointer()] = true
+		}
+		kept := run.inserted[table][:0]
+		for _, record := range run.inserted[table] {
+			if !tracked[reflect.ValueOf(record).Pointer()] {
+				kept = append(kept, record)
+			}
+		}
+		run.inserted[table] = kept
+	})
+	return records
+}
+
+// Forget the records of the table that were deleted, matching them to the
+// deleted rows by primary key, so that they are no longer picked as parents.
+func (d Dumbo) untrack(t testing.TB, db DB, table string, deleted []Record) {
+	t.Helper()
+	if len(deleted) == 0 {
+		return
+	}
+
+	described, err := d.catalog.table(db, table)
+	require.NoError(t, err, fmt.Sprintf("describing table %q", table))
+
+	keys := described.primaryKey
+	if len(keys) == 0 {
+		keys = sortedColumns(deleted[0])
+	}
+	key := indexColumns(keys)
+
+	gone := make(Index, len(deleted))
+	for _, row := range deleted {
+		gone[key(row)] = struct{}{}
+	}
+
+	for _, run := range d.runs {
+		kept := run.inserted[table][:0]
+		for _, record := range run.inserted[table] {
+			if _, isGone := gone[key(record)]; !isGone {
+				kept = append(kept, record)
+			}
+		}
+		run.inserted[table] = kept
+	}
+}
@@ -0,0 +1,151 @@
+package dumbo
+
+import (
+	"testing"
+
+	"github.com/go-faker/faker/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/thebearingedge/dumbo/internal/dumbotest"
+)
+
+func TestAssociatingRecords(t *testing.T) {
+	db := dumbotest.RequireDB(t)
+
+	user := Factory{
+		Table: "user",
+		NewRecord: func(Draw) Record {
+			return Record{
+				"username": faker.Username(),
+			}
+		},
+	}
+
+	post := func(strategy Strategy) Factory {
+		return Factory{
+			Table: "post",
+			NewRecord: func(Draw) Record {
+				return Record{
+					"title": faker.Sentence(),
+				}
+			},
+			Associations: []Association{
+				{Column: "author_id", Strategy: strategy},
+			},
+		}
+	}
+
+	t.Run("creating a parent for every record", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		seeder := New(user, post(Create()))
+
+		posts := seeder.InsertMany(t, tx, "post", []Record{{}, {}})
+
+		assert.NotEqual(t, posts[0]["author_id"], posts[1]["author_id"])
+	})
+
+	t.Run("reusing parents in turn", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		seeder := New(user, post(RoundRobin()))
+
+		users := seeder.InsertMany(t, tx, "user", []Record{{}, {}})
+		posts := seeder.InsertMany(t, tx, "post", []Record{{}, {}, {}, {}})
+
+		assert.Equal(t, users[0]["id"], posts[0]["author_id"])
+		assert.Equal(t, users[1]["id"], posts[1]["author_id"])
+		assert.Equal(t, users[0]["id"], posts[2]["author_id"])
+		assert.Equal(t, users[1]["id"], posts[3]["author_id"])
+	})
+
+	t.Run("reusing random parents", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		seeder := New(user, post(ReuseRandom()))
+
+		users := seeder.InsertMany(t, tx, "user", []Record{{}, {}, {}})
+		posts := seeder.InsertMany(t, tx, "post", []Record{{}, {}, {}, {}, {}})
+
+		ids := []any{users[0]["id"], users[1]["id"], users[2]["id"]}
+		for _, post := range posts {
+			assert.Contains(t, ids, post["author_id"])
+		}
+	})
+
+	t.Run("reusing weighted parents", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		seeder := New(user, post(Weighted(func(r Record) int {
+			if r["username"] == "popular" {
+				return 1
+			}
+			return 0
+		})))
+
+		users := seeder.InsertMany(t, tx, "user", []Record{
+			{"username": "popular"},
+			{"username": "ignored"},
+		})
+		posts := seeder.InsertMany(t, tx, "post", []Record{{}, {}, {}})
+
+		for _, post := range posts {
+			assert.Equal(t, users[0]["id"], post["author_id"])
+		}
+	})
+
+	t.Run("reusing rows already in the table", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		other := New()
+		gopher := other.SeedOne(t, tx, "user", Record{"username": "gopher"})
+
+		seeder := New(user, post(ReuseRandom()))
+
+		posts := seeder.InsertMany(t, tx, "post", []Record{{}, {}})
+
+		assert.Equal(t, gopher["id"], posts[0]["author_id"])
+		assert.Equal(t, gopher["id"], posts[1]["author_id"])
+	})
+
+	t.Run("reusing the parent created for the first record", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		seeder := New(user, post(ReuseRandom()))
+
+		posts := seeder.InsertMany(t, tx, "post", []Record{{}, {}, {}})
+
+		assert.Equal(t, posts[0]["author_id"], posts[1]["author_id"])
+		assert.Equal(t, posts[0]["author_id"], posts[2]["author_id"])
+		assert.Equal(t, 1, seeder.Count(t, tx, "user", Record{}))
+	})
+
+	t.Run("overriding associations", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		seeder := New(user, post(Create()))
+
+		gopher := seeder.InsertOne(t, tx, "user", Record{"username": "gopher"})
+		post := seeder.InsertOne(t, tx, "post", Record{"author_id": gopher["id"]})
+
+		assert.Equal(t, gopher["id"], post["author_id"])
+		assert.Len(t, seeder.FetchMany(t, tx, `select * from "user" where "id" > $1`, gopher["id"]), 0)
+	})
+
+	t.Run("failing on parents without the referenced key", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		projected := user
+		projected.Returning = []string{"username"}
+
+		seeder := New(projected, post(ReuseRandom()))
+
+		recorded := dumbotest.Record(t, func(t testing.TB) {
+			seeder.InsertOne(t, tx, "user", Record{})
+			seeder.InsertOne(t, tx, "post", Record{})
+		})
+
+		assert.True(t, recorded.Failed())
+		assert.Contains(t, recorded.Errors[0], `parent row of table "user" has no column "id" for foreign key "post_author_id_fkey", was it inserted with Returning?`)
+	})
+}
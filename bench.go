@@ -0,0 +1,68 @@
+package dumbo
+
+import (
+	"fmt"
+	"testing"
+)
+
+// An iteration is the TB of one iteration of a benchmark. Its cleanups run
+// when the iteration ends instead of when the benchmark does, and its name
+// is unique so that every iteration draws different random values.
+type iteration struct {
+	testing.TB
+	name     string
+	cleanups []func()
+}
+
+func (it *iteration) Name() string {
+	return it.name
+}
+
+func (it *iteration) Cleanup(f func()) {
+	it.cleanups = append(it.cleanups, f)
+}
+
+func (it *iteration) outer() testing.TB {
+	return it.TB
+}
+
+func (it *iteration) done() {
+	for i := len(it.cleanups) - 1; i >= 0; i-- {
+		it.cleanups[i]()
+	}
+	it.cleanups = nil
+}
+
+// Run seed once with the timer stopped, then run iterate b.N times. Each
+// iteration has a scope of its own, like Run, and its unique keys, tracked
+// records and other cleanups are released with the timer stopped before the
+// next one starts. Iterations that write should do so in a savepoint, e.g.
+// with dumbotest.RequireSavepoint.
+func (d *Dumbo) Benchmark(b *testing.B, seed func(tb testing.TB, d *Dumbo), iterate func(tb testing.TB, d *Dumbo)) {
+	b.Helper()
+
+	b.StopTimer()
+	if seed != nil {
+		seed(b, d)
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		d.iterate(b, i, func(tb testing.TB, d *Dumbo) {
+			b.StartTimer()
+			iterate(tb, d)
+			b.StopTimer()
+		})
+	}
+}
+
+// Run f as the i-th iteration of tb in a scope of its own, then release it.
+func (d *Dumbo) iterate(tb testing.TB, i int, f func(tb testing.TB, d *Dumbo)) {
+	it := &iteration{TB: tb, name: fmt.Sprintf("%v#%v", tb.Name(), i)}
+	d.runs = append(d.runs, newRun())
+
+	f(it, d)
+
+	it.done()
+	d.runs = d.runs[:len(d.runs)-1]
+}
@@ -0,0 +1,61 @@
+package dumbo
+
+import (
+	"testing"
+
+	"github.com/go-faker/faker/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/thebearingedge/dumbo/internal/dumbotest"
+)
+
+func TestBenchmarkIterations(t *testing.T) {
+	seeder := New()
+
+	var (
+		names    []string
+		depths   []int
+		released []string
+	)
+
+	for i := 0; i < 3; i++ {
+		seeder.iterate(t, i, func(tb testing.TB, d *Dumbo) {
+			names = append(names, tb.Name())
+			depths = append(depths, len(d.runs))
+			tb.Cleanup(func() { released = append(released, tb.Name()) })
+		})
+	}
+
+	assert.Equal(t, []string{
+		"TestBenchmarkIterations#0",
+		"TestBenchmarkIterations#1",
+		"TestBenchmarkIterations#2",
+	}, names)
+	assert.Equal(t, names, released)
+	for _, depth := range depths {
+		assert.Equal(t, 2, depth)
+	}
+	assert.Len(t, seeder.runs, 1)
+}
+
+func BenchmarkInsertingUsers(b *testing.B) {
+	db := dumbotest.RequireDB(b)
+	tx := dumbotest.RequireBegin(b, db)
+
+	seeder := New(Factory{
+		Table: "user",
+		NewRecord: func(Draw) Record {
+			return Record{"username": faker.Username()}
+		},
+		UniqueBy: []Indexer{
+			func(r Record) string { return r["username"].(string) },
+		},
+	})
+
+	seeder.Benchmark(b, func(tb testing.TB, d *Dumbo) {
+		d.SeedMany(tb, tx, "user", make([]Record, 10))
+	}, func(tb testing.TB, d *Dumbo) {
+		dumbotest.RequireSavepoint(tb, tx)
+		d.InsertMany(tb, tx, "user", make([]Record, 10))
+	})
+}
@@ -0,0 +1,296 @@
+package dumbo
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/lib/pq"
+)
+
+type column struct {
+	name       string
+	notNull    bool
+	hasDefault bool
+	typ        string
+	category   string
+	element    string
+	fields     []string
+	// identity is "a" for generated always, "d" for by default
+	identity  string
+	generated bool
+	// length is the n of varchar(n) and char(n), 0 when unbounded
+	length int
+	// precision and scale are the p and s of numeric(p, s), 0 when unbounded
+	precision int
+	scale     int
+	// labels are the values of an enum type
+	labels []string
+	// checks are the definitions of check constraints on the column alone
+	checks []string
+}
+
+type foreignKey struct {
+	name       string
+	columns    []string
+	references string
+	keys       []string
+	deferrable bool
+}
+
+type table struct {
+	name        string
+	columns     []column
+	primaryKey  []string
+	foreignKeys []foreignKey
+	// unique are the columns of unique constraints, including the primary key
+	unique [][]string
+}
+
+type catalog struct {
+	mu     sync.Mutex
+	tables map[string]*table
+}
+
+func newCatalog() *catalog {
+	return &catalog{tables: make(map[string]*table)}
+}
+
+// Describe the target table, caching the result for subsequent lookups.
+func (c *catalog) table(db DB, name string) (*table, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if found, ok := c.tables[name]; ok {
+		return found, nil
+	}
+
+	described, err := describe(db, name)
+	if err != nil {
+		return nil, err
+	}
+
+	c.tables[name] = described
+	return described, nil
+}
+
+// The column of the table with the given name.
+func (t *table) column(name string) (column, bool) {
+	for _, c := range t.columns {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return column{}, false
+}
+
+// Sort the columns in table order, followed by any the table lacks by name.
+func (t *table) order(columns []string) []string {
+	position := make(map[string]int, len(t.columns))
+	for i, c := range t.columns {
+		position[c.name] = i
+	}
+	ordered := make([]string, len(columns))
+	copy(ordered, columns)
+	sort.Slice(ordered, func(i, j int) bool {
+		pi, iKnown := position[ordered[i]]
+		pj, jKnown := position[ordered[j]]
+		switch {
+		case iKnown && jKnown:
+			return pi < pj
+		case iKnown != jKnown:
+			return iKnown
+		default:
+			return ordered[i] < ordered[j]
+		}
+	})
+	return ordered
+}
+
+// The foreign keys of the table that point at the target table.
+func (t *table) referencing(target string) []foreignKey {
+	found := make([]foreignKey, 0, 1)
+	for _, fk := range t.foreignKeys {
+		if fk.references == target {
+			found = append(found, fk)
+		}
+	}
+	return found
+}
+
+// The foreign key of the table that includes the column.
+func (t *table) foreignKey(column string) (foreignKey, bool) {
+	for _, fk := range t.foreignKeys {
+		for _, c := range fk.columns {
+			if c == column {
+				return fk, true
+			}
+		}
+	}
+	return foreignKey{}, false
+}
+
+func describe(db DB, name string) (*table, error) {
+	described := &table{name: name}
+
+	rows, err := db.Query(`
+		select a.attname,
+		       a.attnotnull,
+		       a.atthasdef,
+		       a.attidentity,
+		       a.attgenerated <> '',
+		       t.typname,
+		       t.typcategory,
+		       coalesce(e.typname, ''),
+		       array(
+		         select f.attname
+		           from pg_attribute as f
+		          where f.attrelid = t.typrelid
+		            and f.attnum > 0
+		            and not f.attisdropped
+		          order by f.attnum
+		       ),
+		       case
+		         when t.typname in ('varchar', 'bpchar') and a.atttypmod > 0
+		         then a.atttypmod - 4
+		         else 0
+		       end,
+		       case
+		         when t.typname = 'numeric' and a.atttypmod > 0
+		         then (a.atttypmod - 4) >> 16
+		         else 0
+		       end,
+		       case
+		         when t.typname = 'numeric' and a.atttypmod > 0
+		         -- the scale is the low 11 bits, signed
+		         then (((a.atttypmod - 4) & 2047) # 1024) - 1024
+		         else 0
+		       end,
+		       array(
+		         select l.enumlabel
+		           from pg_enum as l
+		          where l.enumtypid = t.oid
+		          order by l.enumsortorder
+		       ),
+		       array(
+		         select pg_get_constraintdef(c.oid)
+		           from pg_constraint as c
+		          where c.conrelid = a.attrelid
+		            and c.contype = 'c'
+		            and c.conkey = array[a.attnum]
+		          order by c.conname
+		       )
+		  from pg_attribute as a
+		  join pg_type as t
+		    on t.oid = a.atttypid
+		  left join pg_type as e
+		    on e.oid = t.typelem
+		   and t.typcategory = 'A'
+		 where a.attrelid = to_regclass(quote_ident($1))
+		   and a.attnum > 0
+		   and not a.attisdropped
+		 order by a.attnum
+	`, name)
+	if err != nil {
+		return nil, fmt.Errorf("describing columns of table %q: %w", name, err)
+	}
+
+	for rows.Next() {
+		var c column
+		err := rows.Scan(
+			&c.name,
+			&c.notNull,
+			&c.hasDefault,
+			&c.identity,
+			&c.generated,
+			&c.typ,
+			&c.category,
+			&c.element,
+			pq.Array(&c.fields),
+			&c.length,
+			&c.precision,
+			&c.scale,
+			pq.Array(&c.labels),
+			pq.Array(&c.checks),
+		)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scanning columns of table %q: %w", name, err)
+		}
+		described.columns = append(described.columns, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scanning columns of table %q: %w", name, err)
+	}
+
+	if len(described.columns) == 0 {
+		return nil, fmt.Errorf("table %q does not exist", name)
+	}
+
+	rows, err = db.Query(`
+		select c.conname,
+		       c.contype,
+		       c.condeferrable,
+		       coalesce(r.relname, ''),
+		       array(
+		         select a.attname
+		           from unnest(c.conkey) with ordinality as k (attnum, position)
+		           join pg_attribute as a
+		             on a.attrelid = c.conrelid
+		            and a.attnum = k.attnum
+		          order by k.position
+		       ),
+		       array(
+		         select a.attname
+		           from unnest(coalesce(c.confkey, '{}')) with ordinality as k (attnum, position)
+		           join pg_attribute as a
+		             on a.attrelid = c.confrelid
+		            and a.attnum = k.attnum
+		          order by k.position
+		       )
+		  from pg_constraint as c
+		  left join pg_class as r
+		    on r.oid = c.confrelid
+		 where c.conrelid = to_regclass(quote_ident($1))
+		   and c.contype in ('p', 'f', 'u')
+		 order by c.conname
+	`, name)
+	if err != nil {
+		return nil, fmt.Errorf("describing constraints of table %q: %w", name, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			fk      foreignKey
+			contype string
+		)
+		err := rows.Scan(
+			&fk.name,
+			&contype,
+			&fk.deferrable,
+			&fk.references,
+			pq.Array(&fk.columns),
+			pq.Array(&fk.keys),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning constraints of table %q: %w", name, err)
+		}
+		switch contype {
+		case "p":
+			described.primaryKey = fk.columns
+			described.unique = append(described.unique, fk.columns)
+		case "u":
+			described.unique = append(described.unique, fk.columns)
+		case "f":
+			described.foreignKeys = append(described.foreignKeys, fk)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scanning constraints of table %q: %w", name, err)
+	}
+
+	return described, nil
+}
@@ -0,0 +1,111 @@
+package dumbo
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type clock struct {
+	mu     sync.Mutex
+	frozen map[string]time.Time
+	last   map[string]time.Time
+}
+
+func newClock() *clock {
+	return &clock{
+		frozen: make(map[string]time.Time),
+		last:   make(map[string]time.Time),
+	}
+}
+
+// Stop the clock of the test and its subtests at the time until the test is
+// done.
+func (d *Dumbo) Freeze(t testing.TB, at time.Time) {
+	name := t.Name()
+
+	d.clock.mu.Lock()
+	previous, wasFrozen := d.clock.frozen[name]
+	d.clock.frozen[name] = at
+	d.clock.mu.Unlock()
+
+	t.Cleanup(func() {
+		d.clock.mu.Lock()
+		defer d.clock.mu.Unlock()
+		if wasFrozen {
+			d.clock.frozen[name] = previous
+		} else {
+			delete(d.clock.frozen, name)
+		}
+	})
+}
+
+// Move the clock of the test forward, freezing it if it was running.
+func (d *Dumbo) Advance(t testing.TB, by time.Duration) {
+	d.Freeze(t, d.Now(t).Add(by))
+}
+
+// The time on the clock of the test, as frozen by the test or the closest of
+// its parents. A clock that is not frozen stands at a time in the five years
+// after 2020 began that is fixed by the seed, so that the same seed generates
+// the same times; freeze it at time.Now() for the current time. Postgres keeps
+// microseconds, so the time is truncated to them.
+func (d *Dumbo) Now(t testing.TB) time.Time {
+	d.clock.mu.Lock()
+	defer d.clock.mu.Unlock()
+
+	names := []string{t.Name()}
+	// iterations of benchmarks and properties follow the clock of their test
+	if it, ok := t.(interface{ outer() testing.TB }); ok {
+		names = append(names, it.outer().Name())
+	}
+
+	for _, name := range names {
+		for {
+			if at, ok := d.clock.frozen[name]; ok {
+				return at.Truncate(time.Microsecond)
+			}
+			i := strings.LastIndex(name, "/")
+			if i < 0 {
+				break
+			}
+			name = name[:i]
+		}
+	}
+
+	span := uint64(5 * 365 * 24 * time.Hour / time.Second)
+	offset := time.Duration(uint64(d.Seed())%span) * time.Second
+	return time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).Add(offset)
+}
+
+// The timestamp of the next row the test generates: the time on its clock,
+// but always later than the last, by Config.Tick at least.
+func (d *Dumbo) tick(t testing.TB) time.Time {
+	now := d.Now(t)
+	name := t.Name()
+
+	step := d.config.Tick
+	if step < time.Microsecond {
+		step = time.Microsecond
+	}
+
+	d.clock.mu.Lock()
+	defer d.clock.mu.Unlock()
+
+	last, ticked := d.clock.last[name]
+	if ticked && now.Before(last.Add(step)) {
+		now = last.Add(step)
+	}
+	d.clock.last[name] = now
+
+	if !ticked {
+		t.Cleanup(func() {
+			d.clock.mu.Lock()
+			delete(d.clock.last, name)
+			d.clock.mu.Unlock()
+		})
+	}
+
+	return now
+}
@@ -0,0 +1,76 @@
+package dumbo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/thebearingedge/dumbo/gen"
+	"github.com/thebearingedge/dumbo/internal/dumbotest"
+)
+
+func TestControllingTheClock(t *testing.T) {
+	at := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("freezing and advancing the clock", func(t *testing.T) {
+		seeder := New()
+
+		seeder.Freeze(t, at)
+		assert.Equal(t, at, seeder.Now(t))
+
+		seeder.Advance(t, time.Hour)
+		assert.Equal(t, at.Add(time.Hour), seeder.Now(t))
+
+		t.Run("inheriting the clock in subtests", func(t *testing.T) {
+			assert.Equal(t, at.Add(time.Hour), seeder.Now(t))
+		})
+	})
+
+	t.Run("starting the clock at a time fixed by the seed", func(t *testing.T) {
+		config := Defaults()
+		config.Seed = 42
+
+		first := NewWithConfig(config)
+		second := NewWithConfig(config)
+
+		assert.Equal(t, first.Now(t), second.Now(t))
+		assert.WithinRange(t, first.Now(t),
+			time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		)
+	})
+
+	t.Run("ticking monotonically", func(t *testing.T) {
+		seeder := New()
+		seeder.Freeze(t, at)
+
+		assert.Equal(t, at, seeder.tick(t))
+		assert.Equal(t, at.Add(time.Second), seeder.tick(t))
+
+		seeder.Advance(t, time.Minute)
+		assert.Equal(t, at.Add(time.Minute), seeder.tick(t))
+	})
+
+	t.Run("stamping generated records", func(t *testing.T) {
+		db := dumbotest.RequireDB(t)
+		tx := dumbotest.RequireBegin(t, db)
+
+		seeder := New(Factory{
+			Table: "listing",
+			Columns: map[string]gen.Generator{
+				"title":    gen.String(1, 12),
+				"quantity": gen.Int(1, 10),
+				"mood":     gen.Enum(),
+			},
+			Timestamps: []string{"listed_at"},
+		})
+		seeder.Freeze(t, at)
+
+		listings := seeder.InsertMany(t, tx, "listing", []Record{{}, {"listed_at": at}, {}})
+
+		assert.True(t, at.Equal(listings[0]["listed_at"].(time.Time)))
+		assert.True(t, at.Equal(listings[1]["listed_at"].(time.Time)))
+		assert.True(t, at.Add(time.Second).Equal(listings[2]["listed_at"].(time.Time)))
+	})
+}
@@ -0,0 +1,168 @@
+package dumbo
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/thebearingedge/dumbo/gen"
+)
+
+var (
+	casts      = regexp.MustCompile(`::[a-z_]+( precision| varying| with time zone| without time zone)?(\[\])?`)
+	comparison = regexp.MustCompile(`^"?(\w+)"? (>=|<=|>|<|=) (-?[\d.]+)$`)
+	reversed   = regexp.MustCompile(`^(-?[\d.]+) (>=|<=|>|<|=) "?(\w+)"?$`)
+	flipped    = map[string]string{">=": "<=", "<=": ">=", ">": "<", "<": ">", "=": "="}
+)
+
+// Describe the column to its generators.
+func (c column) spec() gen.Column {
+	min, max := bounds(c.name, c.checks)
+	return gen.Column{
+		Name:      c.name,
+		Type:      c.typ,
+		Nullable:  !c.notNull,
+		Length:    c.length,
+		Precision: c.precision,
+		Scale:     c.scale,
+		Min:       min,
+		Max:       max,
+		Labels:    c.labels,
+	}
+}
+
+// The tightest range that the check constraints allow for the column. Only
+// comparisons of the column with numbers joined by AND are understood.
+func bounds(name string, checks []string) (min, max *gen.Bound) {
+	for _, check := range checks {
+		check = strings.TrimPrefix(check, "CHECK ")
+		check = strings.TrimSuffix(check, " NOT VALID")
+		if strings.Contains(check, " OR ") {
+			continue
+		}
+		check = casts.ReplaceAllString(check, "")
+		check = strings.NewReplacer("(", "", ")", "", "'", "", "- ", "-").Replace(check)
+
+		for _, term := range strings.Split(check, " AND ") {
+			term = strings.TrimSpace(term)
+			var column, operator, number string
+			if m := comparison.FindStringSubmatch(term); m != nil {
+				column, operator, number = m[1], m[2], m[3]
+			} else if m := reversed.FindStringSubmatch(term); m != nil {
+				column, operator, number = m[3], flipped[m[2]], m[1]
+			} else {
+				continue
+			}
+			if column != name {
+				continue
+			}
+			value, err := strconv.ParseFloat(number, 64)
+			if err != nil {
+				continue
+			}
+			switch operator {
+			case ">", ">=":
+				min = tighter(min, &gen.Bound{Value: value, Exclusive: operator == ">"}, 1)
+			case "<", "<=":
+				max = tighter(max, &gen.Bound{Value: value, Exclusive: operator == "<"}, -1)
+			case "=":
+				min = tighter(min, &gen.Bound{Value: value}, 1)
+				max = tighter(max, &gen.Bound{Value: value}, -1)
+			}
+		}
+	}
+	return min, max
+}
+
+// The bound further in the direction, 1 for lower bounds and -1 for upper.
+func tighter(current, next *gen.Bound, direction float64) *gen.Bound {
+	switch {
+	case current == nil:
+		return next
+	case next.Value*direction > current.Value*direction:
+		return next
+	case next.Value == current.Value && next.Exclusive:
+		return next
+	default:
+		return current
+	}
+}
+
+// A new record from the factory, with values generated for the columns it
+// declares that the record leaves unset. Columns are generated in table
+// order so that the same seed generates the same values.
+func newRecord(r *rand.Rand, now time.Time, factory Factory, described *table) Record {
+	var record Record
+	if factory.NewRecord != nil {
+		record = factory.NewRecord(Draw{Rand: r, Now: now})
+	}
+	if record == nil {
+		record = make(Record, len(factory.Columns))
+	}
+
+	names := make([]string, 0, len(factory.Columns))
+	for name := range factory.Columns {
+		names = append(names, name)
+	}
+
+	for _, name := range described.order(names) {
+		if _, isSet := record[name]; isSet {
+			continue
+		}
+		c, ok := described.column(name)
+		if !ok {
+			c = column{name: name}
+		}
+		record[name] = factory.Columns[name](gen.Field{Column: c.spec(), Rand: r, Now: now})
+	}
+
+	return record
+}
+
+// Copy the partials, filling the NOT NULL columns that the database cannot
+// fill itself with values suggested by the configured heuristics.
+func (d *Dumbo) infer(t testing.TB, db DB, r *rand.Rand, table string, partials []Record) []Record {
+	t.Helper()
+	if len(d.config.Heuristics) == 0 {
+		return partials
+	}
+
+	described, err := d.catalog.table(db, table)
+	require.NoError(t, err, fmt.Sprintf("describing table %q", table))
+
+	generators := make(map[string]gen.Generator)
+	for _, c := range described.columns {
+		if !c.notNull || c.hasDefault || c.identity != "" || c.generated {
+			continue
+		}
+		if _, isForeignKey := described.foreignKey(c.name); isForeignKey {
+			continue
+		}
+		if generator := gen.Infer(d.config.Heuristics, c.spec()); generator != nil {
+			generators[c.name] = generator
+		}
+	}
+
+	now := d.Now(t)
+	inferred := make([]Record, len(partials))
+	for i, partial := range partials {
+		inferred[i] = make(Record, len(partial)+len(generators))
+		for _, c := range described.columns {
+			generator, ok := generators[c.name]
+			if _, isSet := partial[c.name]; ok && !isSet {
+				inferred[i][c.name] = generator(gen.Field{Column: c.spec(), Rand: r, Now: now})
+			}
+		}
+		for column, value := range partial {
+			inferred[i][column] = value
+		}
+	}
+
+	return inferred
+}
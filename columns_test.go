@@ -0,0 +1,102 @@
+package dumbo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/thebearingedge/dumbo/gen"
+	"github.com/thebearingedge/dumbo/internal/dumbotest"
+)
+
+func TestGeneratingColumns(t *testing.T) {
+	db := dumbotest.RequireDB(t)
+
+	since := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	seeder := New(Factory{
+		Table: "listing",
+		Columns: map[string]gen.Generator{
+			"title":     gen.String(1, 100),
+			"quantity":  gen.Int(0, 1000),
+			"rating":    gen.Nullable(0.5, gen.Float(-10, 10)),
+			"mood":      gen.Enum(),
+			"listed_at": gen.Time(since, since.AddDate(0, 1, 0)),
+		},
+	})
+
+	t.Run("generating values that fit the columns", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		listings := seeder.InsertMany(t, tx, "listing", make([]Record, 20))
+
+		for _, listing := range listings {
+			assert.LessOrEqual(t, len(listing["title"].(string)), 12)
+			assert.GreaterOrEqual(t, listing["quantity"], int64(1))
+			assert.LessOrEqual(t, listing["quantity"], int64(10))
+			assert.Contains(t, []string{"happy", "sad"}, listing["mood"])
+			assert.WithinRange(t, listing["listed_at"].(time.Time), since, since.AddDate(0, 1, 0))
+			if rating, ok := listing["rating"].(Decimal); ok {
+				value, err := rating.Float64()
+				assert.NoError(t, err)
+				assert.GreaterOrEqual(t, value, 0.0)
+				assert.LessOrEqual(t, value, 5.0)
+			}
+		}
+	})
+
+	t.Run("preferring partial values", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		listing := seeder.InsertOne(t, tx, "listing", Record{"title": "gopher"})
+
+		assert.Equal(t, "gopher", listing["title"])
+	})
+}
+
+func TestParsingCheckBounds(t *testing.T) {
+	min, max := bounds("quantity", []string{"CHECK (((quantity >= 1) AND (quantity <= 10)))"})
+	assert.Equal(t, &gen.Bound{Value: 1}, min)
+	assert.Equal(t, &gen.Bound{Value: 10}, max)
+
+	min, max = bounds("price", []string{"CHECK ((price > (0)::numeric))"})
+	assert.Equal(t, &gen.Bound{Value: 0, Exclusive: true}, min)
+	assert.Nil(t, max)
+
+	min, max = bounds("level", []string{
+		"CHECK ((level >= '-5'::integer))",
+		"CHECK ((100 > level))",
+		"CHECK ((level > 0))",
+	})
+	assert.Equal(t, &gen.Bound{Value: 0, Exclusive: true}, min)
+	assert.Equal(t, &gen.Bound{Value: 100, Exclusive: true}, max)
+
+	min, max = bounds("level", []string{"CHECK (((level < 0) OR (level > 10)))"})
+	assert.Nil(t, min)
+	assert.Nil(t, max)
+}
+
+func TestInferringColumns(t *testing.T) {
+	db := dumbotest.RequireDB(t)
+
+	config := Defaults()
+	config.Heuristics = append(
+		[]gen.Heuristic{gen.Named("title", gen.Const("gopher"))},
+		gen.DefaultHeuristics()...,
+	)
+
+	seeder := NewWithConfig(config)
+
+	t.Run("filling required columns of tables without a factory", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		listing := seeder.InsertOne(t, tx, "listing", Record{"quantity": 3})
+
+		assert.Equal(t, "gopher", listing["title"])
+		assert.Equal(t, int64(3), listing["quantity"])
+		assert.Contains(t, []string{"happy", "sad"}, listing["mood"])
+		assert.IsType(t, time.Time{}, listing["listed_at"])
+		assert.Nil(t, listing["rating"])
+	})
+}
@@ -0,0 +1,135 @@
+package dumbo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// A Condition matches a column by something other than equality when used
+// as a value of a match Record.
+type Condition struct {
+	operator string
+	values   []any
+}
+
+var (
+	// Match columns that are null.
+	IsNull = Condition{operator: "is null"}
+	// Match columns that are not null.
+	IsNotNull = Condition{operator: "is not null"}
+)
+
+// Match columns equal to any of the values.
+func In(values ...any) Condition {
+	return Condition{operator: "in", values: values}
+}
+
+// Match columns not equal to the value.
+func Ne(value any) Condition {
+	return Condition{operator: "<>", values: []any{value}}
+}
+
+// Match columns greater than the value.
+func Gt(value any) Condition {
+	return Condition{operator: ">", values: []any{value}}
+}
+
+// Match columns greater than or equal to the value.
+func Gte(value any) Condition {
+	return Condition{operator: ">=", values: []any{value}}
+}
+
+// Match columns less than the value.
+func Lt(value any) Condition {
+	return Condition{operator: "<", values: []any{value}}
+}
+
+// Match columns less than or equal to the value.
+func Lte(value any) Condition {
+	return Condition{operator: "<=", values: []any{value}}
+}
+
+// Match columns against a SQL like pattern.
+func Like(pattern string) Condition {
+	return Condition{operator: "like", values: []any{pattern}}
+}
+
+func (c Condition) sql(column string, values []any) (string, []any) {
+	switch c.operator {
+	case "is null", "is not null":
+		return fmt.Sprintf("%q %v", column, c.operator), values
+	case "in":
+		if len(c.values) == 0 {
+			return "false", values
+		}
+		params := make([]string, len(c.values))
+		for i, value := range c.values {
+			values = append(values, value)
+			params[i] = fmt.Sprintf("$%v", len(values))
+		}
+		return fmt.Sprintf("%q in (%v)", column, strings.Join(params, ", ")), values
+	default:
+		values = append(values, c.values[0])
+		return fmt.Sprintf("%q %v $%v", column, c.operator, len(values)), values
+	}
+}
+
+// Build a where clause matching every column of the record, appending its
+// parameters to values encoded for the columns of the table.
+func where(described *table, match Record, values []any) (string, []any, error) {
+	if len(match) == 0 {
+		return "", values, nil
+	}
+
+	columns := sortedColumns(match)
+	conditions := make([]string, len(columns))
+	for i, column := range columns {
+		condition, ok := match[column].(Condition)
+		if !ok {
+			if match[column] == nil {
+				condition = IsNull
+			} else {
+				condition = Condition{operator: "=", values: []any{match[column]}}
+			}
+		}
+		if c, ok := described.column(column); ok && len(condition.values) > 0 {
+			encoded := make([]any, len(condition.values))
+			for j, value := range condition.values {
+				var err error
+				if encoded[j], err = encode(c, value); err != nil {
+					return "", nil, fmt.Errorf("encoding column %q: %w", column, err)
+				}
+			}
+			condition.values = encoded
+		}
+		conditions[i], values = condition.sql(column, values)
+	}
+
+	return fmt.Sprintf("\n\t\t where %v", strings.Join(conditions, "\n\t\t   and ")), values, nil
+}
+
+// Build the where clause for the table, see where.
+func (d Dumbo) where(t testing.TB, db DB, table string, match Record, values []any) (string, []any) {
+	t.Helper()
+
+	described, err := d.catalog.table(db, table)
+	require.NoError(t, err, fmt.Sprintf("describing table %q", table))
+
+	conditions, values, err := where(described, match, values)
+	require.NoError(t, err, fmt.Sprintf("matching rows of table %q", table))
+
+	return conditions, values
+}
+
+func sortedColumns(r Record) []string {
+	columns := make([]string, 0, len(r))
+	for column := range r {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	return columns
+}
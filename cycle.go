@@ -0,0 +1,143 @@
+package dumbo
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// A Side is one of two tables that reference each other. Column is the
+// foreign key column pointing at the other side.
+//
+// Placeholder is written to the column of the first side until the second
+// side exists. It defaults to null, or zero when the column is not null.
+type Side struct {
+	Table       string
+	Column      string
+	Record      Record
+	Placeholder any
+}
+
+// Add a pair of records that reference each other.
+//
+// The foreign keys of both sides must be deferrable and db must be a
+// transaction: the constraints are deferred while the first side holds a
+// placeholder, then checked again once its back-reference is updated.
+func (d *Dumbo) InsertCycle(t testing.TB, db DB, first, second Side) (Record, Record) {
+	t.Helper()
+
+	a, err := d.catalog.table(db, first.Table)
+	require.NoError(t, err, fmt.Sprintf("describing table %q", first.Table))
+	b, err := d.catalog.table(db, second.Table)
+	require.NoError(t, err, fmt.Sprintf("describing table %q", second.Table))
+
+	forward, backward, err := cycleKeys(a, first, b, second)
+	require.NoError(t, err, "planning cyclic insert")
+
+	transaction, err := inTransaction(db)
+	require.NoError(t, err, "checking for a transaction")
+	if !transaction {
+		require.FailNow(t, "inserting a cycle requires db to be a transaction, outside of one the constraints cannot be deferred")
+	}
+
+	constraints := fmt.Sprintf("%q, %q", forward.name, backward.name)
+	_, err = d.exec(t, db, fmt.Sprintf(`set constraints %v deferred`, constraints))
+	require.NoError(t, err, fmt.Sprintf("deferring constraints %v", constraints))
+
+	placeholder := first.Placeholder
+	if placeholder == nil {
+		if c, _ := a.column(first.Column); c.notNull {
+			placeholder = 0
+		}
+	}
+
+	partial := make(Record, len(first.Record)+len(forward.columns))
+	for column, value := range first.Record {
+		partial[column] = value
+	}
+	for _, column := range forward.columns {
+		partial[column] = placeholder
+	}
+	inserted := d.InsertOne(t, db, first.Table, partial)
+
+	partial = make(Record, len(second.Record)+len(backward.columns))
+	for column, value := range second.Record {
+		partial[column] = value
+	}
+	for i, column := range backward.columns {
+		partial[column] = inserted[backward.keys[i]]
+	}
+	other := d.InsertOne(t, db, second.Table, partial)
+
+	sets := make([]string, 0, len(forward.columns))
+	matches := make([]string, 0, len(a.primaryKey))
+	values := make([]any, 0, len(forward.columns)+len(a.primaryKey))
+	for i, column := range forward.columns {
+		values = append(values, other[forward.keys[i]])
+		sets = append(sets, fmt.Sprintf("%q = $%v", column, len(values)))
+	}
+	for _, column := range a.primaryKey {
+		values = append(values, inserted[column])
+		matches = append(matches, fmt.Sprintf("%q = $%v", column, len(values)))
+	}
+
+	updated := d.FetchMany(t, db, fmt.Sprintf(`
+		update %q
+		   set %v
+		 where %v
+		returning *
+	`, first.Table, strings.Join(sets, ", "), strings.Join(matches, " and ")), values...)
+	require.Len(t, updated, 1, fmt.Sprintf("updating back-reference of table %q", first.Table))
+
+	_, err = d.exec(t, db, fmt.Sprintf(`set constraints %v immediate`, constraints))
+	require.NoError(t, err, fmt.Sprintf("checking constraints %v", constraints))
+
+	return updated[0], other
+}
+
+// Find the foreign keys between both sides of a cycle.
+func cycleKeys(a *table, first Side, b *table, second Side) (foreignKey, foreignKey, error) {
+	if len(a.primaryKey) == 0 {
+		return foreignKey{}, foreignKey{}, fmt.Errorf("table %q has no primary key", a.name)
+	}
+
+	keys := make([]foreignKey, 2)
+	for i, side := range []struct {
+		from *table
+		to   *table
+		Side
+	}{{a, b, first}, {b, a, second}} {
+		fk, ok := side.from.foreignKey(side.Column)
+		if !ok || fk.references != side.to.name {
+			return foreignKey{}, foreignKey{}, fmt.Errorf(
+				"column %q of table %q is not a foreign key to table %q",
+				side.Column, side.from.name, side.to.name,
+			)
+		}
+		if !fk.deferrable {
+			return foreignKey{}, foreignKey{}, fmt.Errorf(
+				"foreign key %q of table %q is not deferrable, declare it with \"deferrable\"",
+				fk.name, side.from.name,
+			)
+		}
+		keys[i] = fk
+	}
+
+	return keys[0], keys[1], nil
+}
+
+// Whether db runs in a transaction. Outside of one every statement runs in a
+// transaction of its own, which starts with the statement.
+func inTransaction(db DB) (bool, error) {
+	if _, ok := db.(*sql.Tx); ok {
+		return true, nil
+	}
+	var implicit bool
+	if err := scanOne(db, []any{&implicit}, `select now() = statement_timestamp()`); err != nil {
+		return false, err
+	}
+	return !implicit, nil
+}
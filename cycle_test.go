@@ -0,0 +1,67 @@
+package dumbo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/thebearingedge/dumbo/internal/dumbotest"
+)
+
+func TestInsertingCycles(t *testing.T) {
+	db := dumbotest.RequireDB(t)
+
+	seeder := New()
+
+	t.Run("inserting records that reference each other", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		team, captain := seeder.InsertCycle(t, tx,
+			Side{Table: "team", Column: "captain_id", Record: Record{"name": "gophers"}},
+			Side{Table: "player", Column: "team_id", Record: Record{"username": "gopher"}},
+		)
+
+		assert.Equal(t, "gophers", team["name"])
+		assert.Equal(t, "gopher", captain["username"])
+		assert.Equal(t, captain["id"], team["captain_id"])
+		assert.Equal(t, team["id"], captain["team_id"])
+	})
+
+	t.Run("requiring a transaction", func(t *testing.T) {
+		recorded := dumbotest.Record(t, func(t testing.TB) {
+			seeder.InsertCycle(t, db,
+				Side{Table: "team", Column: "captain_id", Record: Record{"name": "gophers"}},
+				Side{Table: "player", Column: "team_id", Record: Record{"username": "gopher"}},
+			)
+		})
+
+		assert.True(t, recorded.Failed())
+		assert.Contains(t, recorded.Errors[0], "inserting a cycle requires db to be a transaction")
+	})
+
+	t.Run("requiring deferrable foreign keys", func(t *testing.T) {
+		user := &table{
+			name:       "user",
+			columns:    []column{{name: "id", notNull: true}, {name: "team_id"}},
+			primaryKey: []string{"id"},
+			foreignKeys: []foreignKey{
+				{name: "user_team_id_fkey", columns: []string{"team_id"}, references: "team", keys: []string{"id"}},
+			},
+		}
+		team := &table{
+			name:       "team",
+			columns:    []column{{name: "id", notNull: true}, {name: "owner_id", notNull: true}},
+			primaryKey: []string{"id"},
+			foreignKeys: []foreignKey{
+				{name: "team_owner_id_fkey", columns: []string{"owner_id"}, references: "user", keys: []string{"id"}, deferrable: true},
+			},
+		}
+
+		_, _, err := cycleKeys(
+			user, Side{Table: "user", Column: "team_id"},
+			team, Side{Table: "team", Column: "owner_id"},
+		)
+
+		assert.EqualError(t, err, `foreign key "user_team_id_fkey" of table "user" is not deferrable, declare it with "deferrable"`)
+	})
+}
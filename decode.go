@@ -0,0 +1,291 @@
+package dumbo
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// A Decoder converts a value scanned from the database into a Go type that
+// compares reliably with assert.Equal.
+type Decoder func(value any) (any, error)
+
+// A Decimal is the canonical text of a numeric value, without trailing zeros.
+type Decimal string
+
+// Parse the text of a numeric value.
+func NewDecimal(text string) Decimal {
+	text = strings.TrimSpace(text)
+	if strings.Contains(text, ".") && !strings.ContainsAny(text, "eE") {
+		text = strings.TrimRight(text, "0")
+		text = strings.TrimSuffix(text, ".")
+	}
+	if text == "-0" || text == "" {
+		text = "0"
+	}
+	return Decimal(text)
+}
+
+// The exact value of the decimal.
+func (d Decimal) Rat() (*big.Rat, bool) {
+	return new(big.Rat).SetString(string(d))
+}
+
+// The nearest float to the decimal.
+func (d Decimal) Float64() (float64, error) {
+	return strconv.ParseFloat(string(d), 64)
+}
+
+func (d Decimal) String() string {
+	return string(d)
+}
+
+// The decoders used unless configured otherwise: numerics become Decimal,
+// json becomes map[string]any or []any, uuids become strings, and arrays of
+// those or of text, integers, floats and booleans become Go slices, or []any
+// when they have NULL elements or more than one dimension.
+func DefaultDecoders() map[string]Decoder {
+	return map[string]Decoder{
+		"NUMERIC":  DecodeDecimal,
+		"JSON":     DecodeJSON,
+		"JSONB":    DecodeJSON,
+		"UUID":     DecodeText,
+		"_NUMERIC": DecodeDecimalArray,
+		"_JSON":    DecodeJSONArray,
+		"_JSONB":   DecodeJSONArray,
+		"_UUID":    DecodeTextArray,
+		"_TEXT":    DecodeTextArray,
+		"_VARCHAR": DecodeTextArray,
+		"_BPCHAR":  DecodeTextArray,
+		"_NAME":    DecodeTextArray,
+		"_INT2":    DecodeIntArray,
+		"_INT4":    DecodeIntArray,
+		"_INT8":    DecodeIntArray,
+		"_FLOAT4":  DecodeFloatArray,
+		"_FLOAT8":  DecodeFloatArray,
+		"_BOOL":    DecodeBoolArray,
+	}
+}
+
+// Decode a numeric into a Decimal.
+func DecodeDecimal(value any) (any, error) {
+	text, err := asText(value)
+	if err != nil {
+		return nil, err
+	}
+	return NewDecimal(text), nil
+}
+
+// Decode json into map[string]any, []any or a scalar.
+func DecodeJSON(value any) (any, error) {
+	text, err := asText(value)
+	if err != nil {
+		return nil, err
+	}
+	var decoded any
+	if err := json.Unmarshal([]byte(text), &decoded); err != nil {
+		return nil, fmt.Errorf("decoding json: %w", err)
+	}
+	return decoded, nil
+}
+
+// Decode a value into its text.
+func DecodeText(value any) (any, error) {
+	return asText(value)
+}
+
+// Decode an array into []Decimal.
+func DecodeDecimalArray(value any) (any, error) {
+	return decodeArray(value, func(text string) (Decimal, error) {
+		return NewDecimal(text), nil
+	})
+}
+
+// Decode an array into []any of decoded json.
+func DecodeJSONArray(value any) (any, error) {
+	return decodeArray(value, func(text string) (any, error) {
+		return DecodeJSON(text)
+	})
+}
+
+// Decode an array into []string.
+func DecodeTextArray(value any) (any, error) {
+	return decodeArray(value, func(text string) (string, error) {
+		return text, nil
+	})
+}
+
+// Decode an array into []int64.
+func DecodeIntArray(value any) (any, error) {
+	return decodeArray(value, func(text string) (int64, error) {
+		return strconv.ParseInt(text, 10, 64)
+	})
+}
+
+// Decode an array into []float64.
+func DecodeFloatArray(value any) (any, error) {
+	return decodeArray(value, func(text string) (float64, error) {
+		return strconv.ParseFloat(text, 64)
+	})
+}
+
+// Decode an array into []bool.
+func DecodeBoolArray(value any) (any, error) {
+	return decodeArray(value, func(text string) (bool, error) {
+		return text == "t" || text == "true", nil
+	})
+}
+
+// Decode an array into a slice of its parsed elements. Arrays with NULL
+// elements or more than one dimension decode into []any instead, nesting
+// []any for each dimension with nil for NULL.
+func decodeArray[T any](value any, parse func(text string) (T, error)) (any, error) {
+	text, err := asText(value)
+	if err != nil {
+		return nil, err
+	}
+	elements, err := parseArray(text)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := parseElements(elements, parse)
+	if err != nil {
+		return nil, err
+	}
+	typed := make([]T, len(decoded))
+	for i, element := range decoded {
+		if element == nil {
+			return decoded, nil
+		}
+		if _, isNested := element.([]any); isNested {
+			return decoded, nil
+		}
+		typed[i] = element.(T)
+	}
+	return typed, nil
+}
+
+func parseElements[T any](elements []any, parse func(text string) (T, error)) ([]any, error) {
+	parsed := make([]any, len(elements))
+	for i, element := range elements {
+		switch e := element.(type) {
+		case []any:
+			nested, err := parseElements(e, parse)
+			if err != nil {
+				return nil, err
+			}
+			parsed[i] = nested
+		case string:
+			value, err := parse(e)
+			if err != nil {
+				return nil, err
+			}
+			parsed[i] = value
+		}
+	}
+	return parsed, nil
+}
+
+// Split the text of an array into the text of its elements, nesting []any
+// for each dimension, with nil for NULL.
+func parseArray(text string) ([]any, error) {
+	if strings.HasPrefix(text, "[") {
+		// explicit bounds, e.g. [0:1]={a,b}
+		if i := strings.Index(text, "="); i > 0 {
+			text = text[i+1:]
+		}
+	}
+	p := &arrayParser{text: text}
+	elements, err := p.array()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.text) {
+		return nil, fmt.Errorf("unexpected %q after array %q", p.text[p.pos:], text)
+	}
+	return elements, nil
+}
+
+type arrayParser struct {
+	text string
+	pos  int
+}
+
+func (p *arrayParser) array() ([]any, error) {
+	if p.pos >= len(p.text) || p.text[p.pos] != '{' {
+		return nil, fmt.Errorf("expected '{' at %v of array %q", p.pos, p.text)
+	}
+	p.pos++
+	elements := make([]any, 0)
+	if p.pos < len(p.text) && p.text[p.pos] == '}' {
+		p.pos++
+		return elements, nil
+	}
+	for {
+		element, err := p.element()
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, element)
+		if p.pos >= len(p.text) {
+			return nil, fmt.Errorf("unterminated array %q", p.text)
+		}
+		switch p.text[p.pos] {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			return elements, nil
+		default:
+			return nil, fmt.Errorf("unexpected %q at %v of array %q", p.text[p.pos], p.pos, p.text)
+		}
+	}
+}
+
+func (p *arrayParser) element() (any, error) {
+	if p.pos >= len(p.text) {
+		return nil, fmt.Errorf("unterminated array %q", p.text)
+	}
+	if p.text[p.pos] == '{' {
+		return p.array()
+	}
+
+	quoted := p.text[p.pos] == '"'
+	if quoted {
+		p.pos++
+	}
+	var b strings.Builder
+	for p.pos < len(p.text) {
+		c := p.text[p.pos]
+		switch {
+		case c == '\\' && p.pos+1 < len(p.text):
+			b.WriteByte(p.text[p.pos+1])
+			p.pos += 2
+			continue
+		case quoted && c == '"':
+			p.pos++
+			return b.String(), nil
+		case !quoted && (c == ',' || c == '}'):
+			if strings.EqualFold(b.String(), "NULL") {
+				return nil, nil
+			}
+			return b.String(), nil
+		}
+		b.WriteByte(c)
+		p.pos++
+	}
+	return nil, fmt.Errorf("unterminated array %q", p.text)
+}
+
+func asText(value any) (string, error) {
+	switch v := value.(type) {
+	case []byte:
+		return string(v), nil
+	case string:
+		return v, nil
+	default:
+		return "", fmt.Errorf("cannot decode %T as text", value)
+	}
+}
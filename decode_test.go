@@ -0,0 +1,109 @@
+package dumbo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/thebearingedge/dumbo/internal/dumbotest"
+)
+
+func TestDecodingRecords(t *testing.T) {
+	db := dumbotest.RequireDB(t)
+
+	seeder := New()
+
+	t.Run("normalizing fetched values", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		gadget := seeder.FetchOne(t, tx, `
+			insert into "gadget" ("price", "specs", "sku", "tags", "ratings")
+			values (
+			  9.50,
+			  '{"color": "red", "sizes": [1, 2]}',
+			  'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11',
+			  '{go,sql}',
+			  '{4,5}'
+			)
+			returning *
+		`)
+
+		assert.Equal(t, Decimal("9.5"), gadget["price"])
+		assert.Equal(t, map[string]any{"color": "red", "sizes": []any{1.0, 2.0}}, gadget["specs"])
+		assert.Equal(t, "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11", gadget["sku"])
+		assert.Equal(t, []string{"go", "sql"}, gadget["tags"])
+		assert.Equal(t, []int64{4, 5}, gadget["ratings"])
+	})
+
+	t.Run("leaving nulls alone", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		gadget := seeder.FetchOne(t, tx, `
+			insert into "gadget" default values
+			returning *
+		`)
+
+		assert.Nil(t, gadget["price"])
+		assert.Nil(t, gadget["specs"])
+		assert.Nil(t, gadget["tags"])
+	})
+
+	t.Run("plugging in decoders", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		config := Defaults()
+		config.Decoders["NUMERIC"] = func(value any) (any, error) {
+			return NewDecimal(string(value.([]byte))).Float64()
+		}
+		seeder := NewWithConfig(config)
+
+		gadget := seeder.FetchOne(t, tx, `
+			insert into "gadget" ("price")
+			values (9.50)
+			returning *
+		`)
+
+		assert.Equal(t, 9.5, gadget["price"])
+	})
+
+	t.Run("decoding arrays with null elements", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		gadget := seeder.FetchOne(t, tx, `
+			insert into "gadget" ("tags", "ratings")
+			values ('{go,NULL}', '{{1,2},{3,NULL}}')
+			returning *
+		`)
+
+		assert.Equal(t, []any{"go", nil}, gadget["tags"])
+		assert.Equal(t, []any{[]any{int64(1), int64(2)}, []any{int64(3), nil}}, gadget["ratings"])
+	})
+}
+
+func TestDecimals(t *testing.T) {
+	assert.Equal(t, Decimal("1.5"), NewDecimal("1.500"))
+	assert.Equal(t, Decimal("10"), NewDecimal("10.00"))
+	assert.Equal(t, Decimal("100"), NewDecimal("100"))
+	assert.Equal(t, Decimal("0"), NewDecimal("-0.00"))
+	assert.Equal(t, Decimal("NaN"), NewDecimal("NaN"))
+}
+
+func TestDecodingArrays(t *testing.T) {
+	decode := func(decoder Decoder, text string) any {
+		decoded, err := decoder([]byte(text))
+		assert.NoError(t, err)
+		return decoded
+	}
+
+	assert.Equal(t, []string{"go", "a \"b\"", "NULL", ""}, decode(DecodeTextArray, `{go,"a \"b\"","NULL",""}`))
+	assert.Equal(t, []any{"go", nil}, decode(DecodeTextArray, `{go,NULL}`))
+	assert.Equal(t, []int64{}, decode(DecodeIntArray, `{}`))
+	assert.Equal(t, []any{[]any{int64(1), int64(2)}, []any{int64(3), nil}}, decode(DecodeIntArray, `{{1,2},{3,NULL}}`))
+	assert.Equal(t, []float64{1.5, 2}, decode(DecodeFloatArray, `[0:1]={1.5,2}`))
+	assert.Equal(t, []any{true, nil, false}, decode(DecodeBoolArray, `{t,NULL,f}`))
+	assert.Equal(t, []Decimal{"9.5"}, decode(DecodeDecimalArray, `{9.50}`))
+	assert.Equal(t, []any{map[string]any{"lang": "en"}, nil}, decode(DecodeJSONArray, `{"{\"lang\": \"en\"}",NULL}`))
+
+	_, err := DecodeIntArray([]byte(`{1,2`))
+	assert.Error(t, err)
+}
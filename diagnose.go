@@ -0,0 +1,127 @@
+package dumbo
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+var (
+	keyDetail     = regexp.MustCompile(`^Key \((.+)\)=\((.*)\)`)
+	failingDetail = regexp.MustCompile(`^Failing row contains \((.*)\)\.$`)
+)
+
+// Explain why inserting the records failed, with the statement, the values of
+// every row and what Postgres says about the violated constraint, pointing
+// out the row at fault when the detail identifies it.
+func diagnose(described *table, query string, records []Record, err error) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "inserting %v row(s) into table %q: %v\n", len(records), described.name, err)
+
+	offending := -1
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		if pqErr.Constraint != "" {
+			fmt.Fprintf(&b, "\nconstraint: %v", pqErr.Constraint)
+		}
+		if pqErr.Detail != "" {
+			fmt.Fprintf(&b, "\ndetail: %v", pqErr.Detail)
+		}
+		if pqErr.Hint != "" {
+			fmt.Fprintf(&b, "\nhint: %v", pqErr.Hint)
+		}
+		offending = offender(described, records, pqErr.Detail)
+		if offending >= 0 {
+			fmt.Fprintf(&b, "\noffending row: %v of %v", offending+1, len(records))
+		}
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(&b, "\nquery:\n%v\nrows:\n", query)
+	for i, record := range records {
+		marker := " "
+		if i == offending {
+			marker = ">"
+		}
+		values := make([]string, 0, len(record))
+		for _, column := range described.order(sortedColumns(record)) {
+			values = append(values, fmt.Sprintf("%v=%v", column, show(record[column])))
+		}
+		fmt.Fprintf(&b, "%v %v: %v\n", marker, i+1, strings.Join(values, ", "))
+	}
+
+	return b.String()
+}
+
+// The index of the only record matching the detail of the error, or -1.
+func offender(described *table, records []Record, detail string) int {
+	matches := func(record Record) bool { return false }
+
+	if m := keyDetail.FindStringSubmatch(detail); m != nil {
+		columns := strings.Split(m[1], ", ")
+		values := strings.Split(m[2], ", ")
+		if len(columns) != len(values) {
+			return -1
+		}
+		matches = func(record Record) bool {
+			for i, column := range columns {
+				value, ok := record[strings.Trim(column, `"`)]
+				if !ok || text(value) != values[i] {
+					return false
+				}
+			}
+			return true
+		}
+	} else if m := failingDetail.FindStringSubmatch(detail); m != nil {
+		values := strings.Split(m[1], ", ")
+		if len(values) != len(described.columns) {
+			return -1
+		}
+		matches = func(record Record) bool {
+			for i, c := range described.columns {
+				if value, ok := record[c.name]; ok && text(value) != values[i] {
+					return false
+				}
+			}
+			return true
+		}
+	}
+
+	offending := -1
+	for i, record := range records {
+		if matches(record) {
+			if offending >= 0 {
+				return -1
+			}
+			offending = i
+		}
+	}
+	return offending
+}
+
+// The value as Postgres prints it in the detail of an error.
+func text(value any) string {
+	if value == nil {
+		return "null"
+	}
+	return fmt.Sprint(value)
+}
+
+// The value as it is printed in a diagnosis.
+func show(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return strconv.Quote(v)
+	case fmt.Stringer:
+		return strconv.Quote(v.String())
+	default:
+		return fmt.Sprint(v)
+	}
+}
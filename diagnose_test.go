@@ -0,0 +1,68 @@
+package dumbo
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiagnosingInserts(t *testing.T) {
+	post := &table{
+		name: "post",
+		columns: []column{
+			{name: "id"},
+			{name: "author_id"},
+			{name: "title"},
+		},
+	}
+
+	records := []Record{
+		{"author_id": int64(1), "title": "first"},
+		{"author_id": int64(42), "title": "second"},
+	}
+
+	t.Run("pointing out the row of a key violation", func(t *testing.T) {
+		err := &pq.Error{
+			Message:    `insert or update on table "post" violates foreign key constraint "post_author_id_fkey"`,
+			Constraint: "post_author_id_fkey",
+			Detail:     `Key (author_id)=(42) is not present in table "user".`,
+		}
+
+		assert.Equal(t, `inserting 2 row(s) into table "post": pq: insert or update on table "post" violates foreign key constraint "post_author_id_fkey"
+
+constraint: post_author_id_fkey
+detail: Key (author_id)=(42) is not present in table "user".
+offending row: 2 of 2
+
+query:
+insert into "post"
+rows:
+  1: author_id=1, title="first"
+> 2: author_id=42, title="second"
+`, diagnose(post, `insert into "post"`, records, err))
+	})
+
+	t.Run("pointing out the row of a check violation", func(t *testing.T) {
+		detail := `Failing row contains (7, 1, first).`
+
+		assert.Equal(t, 0, offender(post, records, detail))
+	})
+
+	t.Run("pointing out no row when several match", func(t *testing.T) {
+		detail := `Key (title)=(first) already exists.`
+
+		assert.Equal(t, -1, offender(post, append(records, Record{"title": "first"}), detail))
+	})
+
+	t.Run("reporting other errors", func(t *testing.T) {
+		assert.Equal(t, `inserting 1 row(s) into table "post": bad connection
+
+query:
+insert into "post"
+rows:
+  1: title=null
+`, diagnose(post, `insert into "post"`, []Record{{"title": nil}}, errors.New("bad connection")))
+	})
+}
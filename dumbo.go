@@ -3,10 +3,15 @@ package dumbo
 import (
 	"database/sql"
 	"fmt"
+	"math/rand"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/thebearingedge/dumbo/gen"
 )
 
 type DB interface {
@@ -16,47 +21,148 @@ type DB interface {
 
 type Record map[string]any
 
+// Copy the record with only the named columns.
+func (r Record) Pick(columns ...string) Record {
+	picked := make(Record, len(columns))
+	for _, column := range columns {
+		if value, ok := r[column]; ok {
+			picked[column] = value
+		}
+	}
+	return picked
+}
+
+// Copy the record without the named columns.
+func (r Record) Omit(columns ...string) Record {
+	omitted := make(Record, len(r))
+	for column, value := range r {
+		if !slices.Contains(columns, column) {
+			omitted[column] = value
+		}
+	}
+	return omitted
+}
+
 type Indexer func(r Record) string
 
+// A Draw is what a factory builds a new record from. Values drawn from it
+// rather than from elsewhere are the same whenever the seed is.
+type Draw struct {
+	// Rand is the test's random source, see Dumbo.Rand.
+	Rand *rand.Rand
+	// Now is the time on the test's clock, see Dumbo.Now.
+	Now time.Time
+}
+
 type Factory struct {
 	Table     string
-	NewRecord func() Record
-	UniqueBy  []Indexer
+	NewRecord func(d Draw) Record
+	// Columns generate values for the columns that the new record leaves
+	// unset, see package gen.
+	Columns      map[string]gen.Generator
+	UniqueBy     []Indexer
+	Associations []Association
+	// Returning limits the columns of inserted records, all when empty.
+	Returning []string
+	// Timestamps are set to the time on the test's clock for every record
+	// unless a partial sets them, later for each record than the last.
+	Timestamps []string
 }
 
 type Index map[string]any
 
+type run struct {
+	indexes  map[string][]Index
+	inserted map[string][]Record
+}
+
+func newRun() *run {
+	return &run{
+		indexes:  make(map[string][]Index),
+		inserted: make(map[string][]Record),
+	}
+}
+
 type Config struct {
 	retries int
+	// Decoders convert fetched values by database type name, e.g. "NUMERIC".
+	// Undecoded bytes of any type but "BYTEA" are fetched as strings, as is
+	// every enum, range and composite since the driver does not name them.
+	Decoders map[string]Decoder
+	// CacheStatements prepares inserts once per DB and shape of records.
+	CacheStatements bool
+	// Tracer receives every statement run, e.g. LogTracer{}.
+	Tracer Tracer
+	// Seed fixes the random values generated, see Dumbo.Seed.
+	Seed int64
+	// Tick is the least time between the timestamps of records, see
+	// Factory.Timestamps.
+	Tick time.Duration
+	// Heuristics fill the NOT NULL columns of tables without a factory,
+	// e.g. gen.DefaultHeuristics(). None are used unless configured.
+	Heuristics []gen.Heuristic
 }
 
 func Defaults() Config {
 	return Config{
-		retries: 5,
+		retries:  5,
+		Decoders: DefaultDecoders(),
+		Tick:     time.Second,
+	}
+}
+
+// An Option changes how records are inserted.
+type Option func(o *options)
+
+type options struct {
+	conflict  *conflict
+	returning []string
+}
+
+// Return only the named columns of inserted records.
+func Returning(columns ...string) Option {
+	return func(o *options) {
+		o.returning = columns
 	}
 }
 
+func newOptions(opts []Option) options {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
 type Dumbo struct {
-	factories map[string]Factory
-	runs      []map[string][]Index
-	config    Config
+	factories  map[string]Factory
+	runs       []*run
+	config     Config
+	catalog    *catalog
+	statements *statements
+	random     *randomness
+	clock      *clock
 }
 
 func New(factories ...Factory) Dumbo {
 	d := Dumbo{
-		factories: make(map[string]Factory, len(factories)),
-		runs:      make([]map[string][]Index, 0, 1),
-		config:    Defaults(),
+		factories:  make(map[string]Factory, len(factories)),
+		runs:       make([]*run, 0, 1),
+		config:     Defaults(),
+		catalog:    newCatalog(),
+		statements: newStatements(),
+		random:     newRandomness(),
+		clock:      newClock(),
 	}
 
-	run := make(map[string][]Index)
+	run := newRun()
 
 	for _, factory := range factories {
 		indexes := make([]Index, len(factory.UniqueBy))
 		for i := range factory.UniqueBy {
 			indexes[i] = make(Index)
 		}
-		run[factory.Table] = indexes
+		run.indexes[factory.Table] = indexes
 		d.factories[factory.Table] = factory
 	}
 
@@ -72,114 +178,311 @@ func NewWithConfig(config Config, factories ...Factory) Dumbo {
 }
 
 // Truncate the target table before inserting the record.
-func (d *Dumbo) SeedOne(t *testing.T, db DB, table string, partial Record) Record {
-	return d.SeedMany(t, db, table, []Record{partial})[0]
+func (d *Dumbo) SeedOne(t testing.TB, db DB, table string, partial Record, opts ...Option) Record {
+	return d.SeedMany(t, db, table, []Record{partial}, opts...)[0]
 }
 
 // Truncate the target table before inserting the records.
-func (d *Dumbo) SeedMany(t *testing.T, db DB, table string, partials []Record) []Record {
+func (d *Dumbo) SeedMany(t testing.TB, db DB, table string, partials []Record, opts ...Option) []Record {
 	t.Helper()
-	_, err := db.Exec(fmt.Sprintf(`truncate table %q restart identity cascade`, table))
+	_, err := d.exec(t, db, fmt.Sprintf(`truncate table %q restart identity cascade`, table))
 	require.NoError(t, err, fmt.Sprintf("truncating table %q", table))
 
-	return d.InsertMany(t, db, table, partials)
+	// the truncate cascades, so rows of other tables may be gone too
+	for _, run := range d.runs {
+		run.inserted = make(map[string][]Record)
+	}
+
+	return d.InsertMany(t, db, table, partials, opts...)
 }
 
 // Add a record to the target table.
-func (d *Dumbo) InsertOne(t *testing.T, db DB, table string, partial Record) Record {
-	return d.InsertMany(t, db, table, []Record{partial})[0]
+func (d *Dumbo) InsertOne(t testing.TB, db DB, table string, partial Record, opts ...Option) Record {
+	return d.InsertMany(t, db, table, []Record{partial}, opts...)[0]
 }
 
 // Add records to the target table.
-func (d *Dumbo) InsertMany(t *testing.T, db DB, table string, partials []Record) []Record {
+func (d *Dumbo) InsertMany(t testing.TB, db DB, table string, partials []Record, opts ...Option) []Record {
 	t.Helper()
+	return d.insertMany(t, db, table, nil, partials, opts...)
+}
+
+// Add records to the target table, unique by the indexers of its factory
+// followed by those given for this insert alone.
+func (d *Dumbo) insertMany(t testing.TB, db DB, table string, uniqueBy []Indexer, partials []Record, opts ...Option) []Record {
+	t.Helper()
+	o := newOptions(opts)
+
+	r := d.Rand(t)
+
+	scan := func(dest []any, query string, values ...any) error {
+		return d.scanOne(t, db, dest, query, values...)
+	}
+	partials, err := resolve(scan, r, partials)
+	require.NoError(t, err, fmt.Sprintf("resolving references for table %q", table))
+
 	factory, hasFactory := d.factories[table]
 	if !hasFactory {
-		return insert(t, db, table, partials)
+		partials = d.infer(t, db, r, table, partials)
+		if len(uniqueBy) == 0 {
+			return d.track(t, table, d.insert(t, db, table, partials, o))
+		}
+		factory = Factory{Table: table}
 	}
+	factory.UniqueBy = append(factory.UniqueBy[:len(factory.UniqueBy):len(factory.UniqueBy)], uniqueBy...)
 
 	run := d.runs[len(d.runs)-1]
-	_, hasIndexes := run[table]
-	if !hasIndexes {
-		run[table] = make([]Index, len(factory.UniqueBy))
-		for i := range factory.UniqueBy {
-			run[table][i] = make(Index)
-		}
+	for len(run.indexes[table]) < len(factory.UniqueBy) {
+		run.indexes[table] = append(run.indexes[table], make(Index))
+	}
+
+	partials = d.associate(t, db, r, factory, partials)
+
+	if len(o.returning) == 0 {
+		o.returning = factory.Returning
 	}
 
-	records, indexed, err := generate(d.runs, d.config.retries, factory, partials)
+	described, err := d.catalog.table(db, table)
+	require.NoError(t, err, fmt.Sprintf("describing table %q", table))
+
+	records, indexed, err := generate(d.runs, d.config.retries, r, d.Now(t), factory, described, partials, o.conflict != nil)
 	t.Cleanup(func() {
-		for i, key := range indexed {
-			delete(run[table][i], key)
+		for i, keys := range indexed {
+			for _, key := range keys {
+				delete(run.indexes[table][i], key)
+			}
 		}
 	})
 	if err != nil {
 		panic(err)
 	}
-	return insert(t, db, table, records)
+
+	for i, record := range records {
+		var stamp time.Time
+		for _, column := range factory.Timestamps {
+			if _, isSet := partials[i][column]; !isSet {
+				if stamp.IsZero() {
+					stamp = d.tick(t)
+				}
+				record[column] = stamp
+			}
+		}
+	}
+
+	// the database fills these itself unless a partial insists
+	for i, record := range records {
+		for _, c := range described.columns {
+			if _, isSet := partials[i][c.name]; !isSet && (c.generated || c.identity == "a") {
+				delete(record, c.name)
+			}
+		}
+	}
+
+	return d.track(t, table, d.insert(t, db, table, records, o))
 }
 
-// Select one row from the table
-func (d Dumbo) FetchOne(t *testing.T, db DB, query string, values ...any) Record {
+// Select exactly one row from the table
+func (d Dumbo) FetchOne(t testing.TB, db DB, query string, values ...any) Record {
 	t.Helper()
-	return d.FetchMany(t, db, query, values...)[0]
+	return d.FetchExactly(t, db, 1, query, values...)[0]
+}
+
+// Select zero or one row from the table, nil when there is none
+func (d Dumbo) FetchOptional(t testing.TB, db DB, query string, values ...any) Record {
+	t.Helper()
+	fetched := d.FetchMany(t, db, query, values...)
+	if len(fetched) > 1 {
+		require.FailNow(t, fmt.Sprintf("expected at most 1 row, got %v running query:\n\n%v", len(fetched), query))
+	}
+	if len(fetched) == 0 {
+		return nil
+	}
+	return fetched[0]
+}
+
+// Run query and require exactly n rows
+func (d Dumbo) FetchExactly(t testing.TB, db DB, n int, query string, values ...any) []Record {
+	t.Helper()
+	fetched := d.FetchMany(t, db, query, values...)
+	if len(fetched) != n {
+		require.FailNow(t, fmt.Sprintf("expected %v row(s), got %v running query:\n\n%v", n, len(fetched), query))
+	}
+	return fetched
 }
 
 // Run query and return all rows
-func (d Dumbo) FetchMany(t *testing.T, db DB, query string, values ...any) []Record {
+func (d Dumbo) FetchMany(t testing.TB, db DB, query string, values ...any) []Record {
 	t.Helper()
-	rows, err := db.Query(query, values...)
+	fetched, err := d.fetch(t, db, query, values...)
 	require.NoError(t, err, fmt.Sprintf("running query:\n\n%v", query))
-	defer rows.Close()
-
-	return fetchAll(t, rows)
+	return fetched
 }
 
 // Remove unique indexes from sub-test when done.
-func (d *Dumbo) Run(t *testing.T, r func(d *Dumbo)) {
+func (d *Dumbo) Run(t testing.TB, r func(d *Dumbo)) {
 	t.Helper()
-	d.runs = append(d.runs, make(map[string][]Index))
+	d.runs = append(d.runs, newRun())
 	t.Cleanup(func() { d.runs = d.runs[:len(d.runs)-1] })
 	r(d)
 }
 
-func insert(t *testing.T, db DB, table string, records []Record) []Record {
+// Insert the records in order, batching consecutive records that set the same
+// columns, since a statement inserts the same columns for every row.
+func (d *Dumbo) insert(t testing.TB, db DB, table string, records []Record, o options) []Record {
+	inserted := make([]Record, 0, len(records))
+	for start := 0; start < len(records); {
+		end := start + 1
+		for end < len(records) && sameColumns(records[start], records[end]) {
+			end++
+		}
+		inserted = append(inserted, d.insertBatch(t, db, table, records[start:end], o)...)
+		start = end
+	}
+	return inserted
+}
+
+func sameColumns(a, b Record) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for column := range a {
+		if _, ok := b[column]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (d *Dumbo) insertBatch(t testing.TB, db DB, table string, records []Record, o options) []Record {
+	described, err := d.catalog.table(db, table)
+	require.NoError(t, err, fmt.Sprintf("describing table %q", table))
+
+	query, values, returning, err := buildInsert(described, records, o)
+	require.NoError(t, err, fmt.Sprintf("building insert into table %q", table))
+
+	finish := d.trace(t, query, values)
+	rows, err := d.query(t, db, query, values...)
+	if err != nil {
+		finish(0, err)
+		require.FailNow(t, diagnose(described, query, records, err))
+	}
+	defer rows.Close()
+
+	inserted := fetchAll(t, rows, d.config.Decoders)
+	finish(int64(len(inserted)), nil)
+	if o.conflict == nil {
+		return inserted
+	}
+
+	reconciled := d.reconcile(t, db, table, o.conflict.target, returning, records, inserted)
+	if len(o.returning) == 0 {
+		return reconciled
+	}
+
+	picked := make([]Record, len(reconciled))
+	for i, record := range reconciled {
+		picked[i] = record.Pick(o.returning...)
+	}
+	return picked
+}
+
+// Build the insert statement for the records, with the columns of the first
+// record in table order so that records of the same shape share a statement.
+func buildInsert(described *table, records []Record, o options) (string, []any, []string, error) {
 	first := records[0]
 
 	keys := make([]string, 0, len(first))
-	columns := make([]string, 0, len(first))
 	for column := range first {
 		keys = append(keys, column)
-		columns = append(columns, fmt.Sprintf("%q", column))
+	}
+	keys = described.order(keys)
+
+	overriding := ""
+	columns := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if c, ok := described.column(key); ok {
+			if c.generated {
+				return "", nil, nil, fmt.Errorf("column %q is generated and cannot be inserted", key)
+			}
+			if c.identity == "a" {
+				overriding = "\n\t\toverriding system value"
+			}
+		}
+		columns = append(columns, fmt.Sprintf("%q", key))
 	}
 
 	params := make([]string, 0, len(records))
-	values := make([]any, 0, len(records))
+	values := make([]any, 0, len(records)*len(keys))
 	p := 1
 
 	for _, record := range records {
-		tuple := make([]string, 0, len(first))
+		tuple := make([]string, 0, len(keys))
 		for _, key := range keys {
-			values = append(values, record[key])
+			value := record[key]
+			if c, ok := described.column(key); ok {
+				encoded, err := encode(c, value)
+				if err != nil {
+					return "", nil, nil, fmt.Errorf("encoding column %q: %w", key, err)
+				}
+				value = encoded
+			}
+			values = append(values, value)
 			tuple = append(tuple, fmt.Sprintf("$%v", p))
 			p++
 		}
 		params = append(params, fmt.Sprintf("(%v)", strings.Join(tuple, ", ")))
 	}
 
-	rows, err := db.Query(fmt.Sprintf(`
-		insert into %v (%v)
-		values %v
-		returning *
-	`, fmt.Sprintf("%q", table), strings.Join(columns, ", "), strings.Join(params, ", ")), values...)
-	require.NoError(t, err, fmt.Sprintf("inserting row(s) into table %q", table))
+	// records without columns are all defaults, spelled out for one column
+	// since default values cannot insert more than one row
+	if len(keys) == 0 && len(described.columns) > 0 {
+		columns = append(columns, fmt.Sprintf("%q", described.columns[0].name))
+		for i := range params {
+			params[i] = "(default)"
+		}
+	}
+
+	onConflict := ""
+	returning := o.returning
+	if o.conflict != nil {
+		if len(o.conflict.target) == 0 {
+			return "", nil, nil, fmt.Errorf("upserting into table %q requires conflict target columns", described.name)
+		}
+		onConflict = o.conflict.clause(keys)
+		if len(returning) > 0 {
+			// the conflict target is needed to line up the rows with the records
+			for _, column := range o.conflict.target {
+				if !slices.Contains(returning, column) {
+					returning = append(returning[:len(returning):len(returning)], column)
+				}
+			}
+		}
+	}
+
+	query := fmt.Sprintf(`
+		insert into %v (%v)%v
+		values %v%v
+		returning %v
+	`, fmt.Sprintf("%q", described.name), strings.Join(columns, ", "), overriding, strings.Join(params, ", "), onConflict, selection(returning))
 
-	return fetchAll(t, rows)
+	return query, values, returning, nil
+}
+
+// Quote the columns for a select list, all of them when empty.
+func selection(columns []string) string {
+	if len(columns) == 0 {
+		return "*"
+	}
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = fmt.Sprintf("%q", column)
+	}
+	return strings.Join(quoted, ", ")
 }
 
-func generate(runs []map[string][]Index, retries int, factory Factory, partials []Record) ([]Record, map[int]string, error) {
+func generate(runs []*run, retries int, r *rand.Rand, now time.Time, factory Factory, described *table, partials []Record, upsert bool) ([]Record, map[int][]string, error) {
 
-	indexed := make(map[int]string)
+	indexed := make(map[int][]string)
 	records := make([]Record, 0, len(partials))
 
 EACH_PARTIAL:
@@ -194,30 +497,42 @@ EACH_PARTIAL:
 					retries,
 					factory.Table,
 				)
-				return nil, nil, err
+				return nil, indexed, err
 			}
 
-			record := factory.NewRecord()
+			record := newRecord(r, now, factory, described)
 			for column, value := range partial {
 				record[column] = value
 			}
 
-			for i, run := range runs {
-				indexes := run[factory.Table]
-				for j, uniqueBy := range factory.UniqueBy {
-
-					key := uniqueBy(record)
-					if _, exists := indexes[j][key]; exists {
-						attempts++
-						continue EACH_RECORD
-					}
-					if i == len(runs)-1 {
-						indexed[j] = key
-						indexes[j][key] = struct{}{}
+			keys := make([]string, len(factory.UniqueBy))
+			fresh := make([]bool, len(factory.UniqueBy))
+			for j, uniqueBy := range factory.UniqueBy {
+				keys[j] = uniqueBy(record)
+				fresh[j] = true
+				for _, run := range runs {
+					if indexes := run.indexes[factory.Table]; j < len(indexes) {
+						if _, exists := indexes[j][keys[j]]; exists {
+							if upsert {
+								// the database resolves the conflict
+								fresh[j] = false
+								break
+							}
+							attempts++
+							continue EACH_RECORD
+						}
 					}
 				}
 			}
 
+			indexes := runs[len(runs)-1].indexes[factory.Table]
+			for j, key := range keys {
+				if fresh[j] {
+					indexed[j] = append(indexed[j], key)
+					indexes[j][key] = struct{}{}
+				}
+			}
+
 			records = append(records, record)
 
 			continue EACH_PARTIAL
@@ -227,10 +542,13 @@ EACH_PARTIAL:
 	return records, indexed, nil
 }
 
-func fetchAll(t *testing.T, rows *sql.Rows) []Record {
+func fetchAll(t testing.TB, rows *sql.Rows, decoders map[string]Decoder) []Record {
 	columns, err := rows.Columns()
 	require.NoError(t, err, "reading columns returned from query")
 
+	types, err := rows.ColumnTypes()
+	require.NoError(t, err, "reading column types returned from query")
+
 	fetched := make([]Record, 0)
 
 	for rows.Next() {
@@ -243,7 +561,15 @@ func fetchAll(t *testing.T, rows *sql.Rows) []Record {
 
 		record := make(Record, len(columns))
 		for i, column := range columns {
-			record[column] = fields[i]
+			value := fields[i]
+			name := strings.ToUpper(types[i].DatabaseTypeName())
+			if decode, ok := decoders[name]; ok && value != nil {
+				value, err = decode(value)
+				require.NoError(t, err, fmt.Sprintf("decoding column %q returned from query", column))
+			} else if bytes, ok := value.([]byte); ok && name != "BYTEA" {
+				value = string(bytes)
+			}
+			record[column] = value
 		}
 
 		fetched = append(fetched, record)
@@ -109,6 +109,106 @@ func TestFetchingRecords(t *testing.T) {
 		assert.Equal(t, int64(2), rustacean["id"])
 		assert.Equal(t, "rustacean", rustacean["username"])
 	})
+
+	t.Run("fetching an optional record", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		seeder.SeedOne(t, tx, "user", Record{"username": "gopher"})
+
+		gopher := seeder.FetchOptional(t, tx, `select * from "user" where "username" = $1`, "gopher")
+		missing := seeder.FetchOptional(t, tx, `select * from "user" where "username" = $1`, "rustacean")
+
+		assert.Equal(t, "gopher", gopher["username"])
+		assert.Nil(t, missing)
+	})
+
+	t.Run("fetching an exact number of records", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		seeder.SeedMany(t, tx, "user", []Record{
+			{"username": "gopher"},
+			{"username": "rustacean"},
+		})
+
+		users := seeder.FetchExactly(t, tx, 2, `select * from "user" order by "id"`)
+
+		assert.Equal(t, "gopher", users[0]["username"])
+		assert.Equal(t, "rustacean", users[1]["username"])
+	})
+
+	t.Run("failing to fetch a missing record", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		recorded := dumbotest.Record(t, func(t testing.TB) {
+			seeder.FetchOne(t, tx, `select * from "user" where "username" = $1`, "gopher")
+		})
+
+		assert.True(t, recorded.Failed())
+		assert.Contains(t, recorded.Errors[0], "expected 1 row(s), got 0 running query:")
+		assert.Contains(t, recorded.Errors[0], `select * from "user" where "username" = $1`)
+	})
+
+	t.Run("failing to fetch one of many records", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		seeder.SeedMany(t, tx, "user", []Record{
+			{"username": "gopher"},
+			{"username": "rustacean"},
+		})
+
+		recorded := dumbotest.Record(t, func(t testing.TB) {
+			seeder.FetchOne(t, tx, `select * from "user"`)
+		})
+
+		assert.True(t, recorded.Failed())
+		assert.Contains(t, recorded.Errors[0], "expected 1 row(s), got 2 running query:")
+	})
+
+	t.Run("failing to fetch an exact number of missing records", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		seeder.SeedOne(t, tx, "user", Record{"username": "gopher"})
+
+		recorded := dumbotest.Record(t, func(t testing.TB) {
+			seeder.FetchExactly(t, tx, 2, `select * from "user"`)
+		})
+
+		assert.True(t, recorded.Failed())
+		assert.Contains(t, recorded.Errors[0], "expected 2 row(s), got 1 running query:")
+	})
+
+	t.Run("failing to fetch an exact number of extra records", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		seeder.SeedMany(t, tx, "user", []Record{
+			{"username": "gopher"},
+			{"username": "rustacean"},
+			{"username": "pythonista"},
+		})
+
+		recorded := dumbotest.Record(t, func(t testing.TB) {
+			seeder.FetchExactly(t, tx, 2, `select * from "user"`)
+		})
+
+		assert.True(t, recorded.Failed())
+		assert.Contains(t, recorded.Errors[0], "expected 2 row(s), got 3 running query:")
+	})
+
+	t.Run("failing to fetch an optional record among many", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		seeder.SeedMany(t, tx, "user", []Record{
+			{"username": "gopher"},
+			{"username": "rustacean"},
+		})
+
+		recorded := dumbotest.Record(t, func(t testing.TB) {
+			seeder.FetchOptional(t, tx, `select * from "user"`)
+		})
+
+		assert.True(t, recorded.Failed())
+		assert.Contains(t, recorded.Errors[0], "expected at most 1 row, got 2 running query:")
+	})
 }
 
 func TestGeneratingRecordFields(t *testing.T) {
@@ -117,7 +217,7 @@ func TestGeneratingRecordFields(t *testing.T) {
 	seeder := New(
 		Factory{
 			Table: "user",
-			NewRecord: func() Record {
+			NewRecord: func(Draw) Record {
 				return Record{
 					"username": faker.Username(),
 				}
@@ -173,7 +273,7 @@ func TestRetriesConfiguration(t *testing.T) {
 		config,
 		Factory{
 			Table: "user",
-			NewRecord: func() Record {
+			NewRecord: func(Draw) Record {
 				return Record{
 					"username": faker.Username(),
 				}
@@ -202,7 +302,7 @@ func TestGeneratingUniqueRecords(t *testing.T) {
 	seeder := New(
 		Factory{
 			Table: "user",
-			NewRecord: func() Record {
+			NewRecord: func(Draw) Record {
 				return Record{
 					"username": faker.Username(),
 				}
@@ -244,7 +344,7 @@ func TestNestedRuns(t *testing.T) {
 	seeder := New(
 		Factory{
 			Table: "user",
-			NewRecord: func() Record {
+			NewRecord: func(Draw) Record {
 				return Record{
 					"username": faker.Username(),
 				}
@@ -289,3 +389,169 @@ func TestNestedRuns(t *testing.T) {
 		})
 	})
 }
+
+func TestReturningColumns(t *testing.T) {
+	db := dumbotest.RequireDB(t)
+
+	t.Run("returning columns per call", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		seeder := New()
+
+		gopher := seeder.InsertOne(t, tx, "user", Record{"username": "gopher"}, Returning("username"))
+
+		assert.Equal(t, Record{"username": "gopher"}, gopher)
+	})
+
+	t.Run("returning columns per factory", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		seeder := New(
+			Factory{
+				Table: "user",
+				NewRecord: func(Draw) Record {
+					return Record{
+						"username": faker.Username(),
+					}
+				},
+				Returning: []string{"id"},
+			},
+		)
+
+		user := seeder.InsertOne(t, tx, "user", Record{})
+		gopher := seeder.InsertOne(t, tx, "user", Record{"username": "gopher"}, Returning("username"))
+
+		assert.Equal(t, []string{"id"}, sortedColumns(user))
+		assert.Equal(t, Record{"username": "gopher"}, gopher)
+	})
+
+	t.Run("returning columns of upserts", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		seeder := New()
+
+		gopher := seeder.InsertOne(t, tx, "user", Record{"username": "gopher"})
+		upserted := seeder.InsertOne(t, tx, "user", Record{"username": "gopher"},
+			OnConflictDoNothing("username"),
+			Returning("id"),
+		)
+
+		assert.Equal(t, Record{"id": gopher["id"]}, upserted)
+	})
+}
+
+func TestProjectingRecords(t *testing.T) {
+	user := Record{"id": int64(1), "username": "gopher", "password": "secret"}
+
+	assert.Equal(t, Record{"id": int64(1), "username": "gopher"}, user.Pick("id", "username", "email"))
+	assert.Equal(t, Record{"id": int64(1), "username": "gopher"}, user.Omit("password"))
+}
+
+func TestBuildingInserts(t *testing.T) {
+	post := &table{
+		name: "post",
+		columns: []column{
+			{name: "id"},
+			{name: "author_id"},
+			{name: "title"},
+		},
+	}
+
+	records := []Record{
+		{"title": "first", "author_id": 1, "draft": true},
+		{"title": "second", "author_id": 2, "draft": false},
+	}
+
+	for i := 0; i < 10; i++ {
+		query, values, _, err := buildInsert(post, records, options{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, `
+		insert into "post" ("author_id", "title", "draft")
+		values ($1, $2, $3), ($4, $5, $6)
+		returning *
+	`, query)
+		assert.Equal(t, []any{1, "first", true, 2, "second", false}, values)
+	}
+}
+
+func TestBuildingDefaultInserts(t *testing.T) {
+	gadget := &table{name: "gadget", columns: []column{{name: "id"}, {name: "price"}}}
+
+	query, values, _, err := buildInsert(gadget, []Record{{}, {}}, options{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, `
+		insert into "gadget" ("id")
+		values (default), (default)
+		returning *
+	`, query)
+	assert.Empty(t, values)
+}
+
+func TestGeneratedColumns(t *testing.T) {
+	db := dumbotest.RequireDB(t)
+
+	seeder := New(
+		Factory{
+			Table: "ticket",
+			NewRecord: func(Draw) Record {
+				return Record{
+					"id":       int64(1),
+					"price":    10,
+					"quantity": 2,
+					"total":    0,
+				}
+			},
+		},
+	)
+
+	t.Run("leaving generated columns to the database", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		ticket := seeder.InsertOne(t, tx, "ticket", Record{"quantity": 3})
+
+		assert.NotNil(t, ticket["id"])
+		assert.Equal(t, int64(30), ticket["total"])
+	})
+
+	t.Run("overriding identity columns", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		ticket := seeder.InsertOne(t, tx, "ticket", Record{"id": int64(42)})
+
+		assert.Equal(t, int64(42), ticket["id"])
+	})
+
+	t.Run("overriding identity columns of some records", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		tickets := seeder.InsertMany(t, tx, "ticket", []Record{{"id": int64(42)}, {}, {}})
+
+		assert.Len(t, tickets, 3)
+		assert.Equal(t, int64(42), tickets[0]["id"])
+		assert.NotNil(t, tickets[1]["id"])
+		assert.NotNil(t, tickets[2]["id"])
+	})
+
+	t.Run("rejecting values for generated columns", func(t *testing.T) {
+		ticket := &table{
+			name: "ticket",
+			columns: []column{
+				{name: "id", identity: "a"},
+				{name: "price"},
+				{name: "quantity"},
+				{name: "total", generated: true},
+			},
+		}
+
+		_, _, _, err := buildInsert(ticket, []Record{{"price": 1, "quantity": 1, "total": 1}}, options{})
+
+		assert.EqualError(t, err, `column "total" is generated and cannot be inserted`)
+
+		query, _, _, err := buildInsert(ticket, []Record{{"id": 1, "price": 1, "quantity": 1}}, options{})
+
+		assert.NoError(t, err)
+		assert.Contains(t, query, "overriding system value")
+	})
+}
@@ -0,0 +1,154 @@
+package dumbo
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// A Range is a value of a Postgres range type. Nil bounds are unbounded and
+// Bounds defaults to "[)".
+type Range struct {
+	Lower  any
+	Upper  any
+	Bounds string
+}
+
+// Convert a value into something the driver can send for the column: slices
+// become arrays, maps and structs become json, and Range or struct values
+// become range and composite literals.
+func encode(c column, value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if _, ok := value.(driver.Valuer); ok {
+		return value, nil
+	}
+
+	switch c.category {
+	case "A":
+		return encodeArray(c, value)
+	case "R":
+		if r, ok := value.(Range); ok {
+			return encodeRange(r), nil
+		}
+	case "C":
+		return encodeComposite(c, value)
+	}
+
+	switch c.typ {
+	case "json", "jsonb":
+		return encodeJSON(value)
+	}
+
+	if s, ok := value.(fmt.Stringer); ok && c.category == "E" {
+		return s.String(), nil
+	}
+
+	return value, nil
+}
+
+func encodeJSON(value any) (any, error) {
+	switch v := value.(type) {
+	case string, []byte, json.RawMessage:
+		return v, nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("encoding json: %w", err)
+	}
+	return string(encoded), nil
+}
+
+func encodeArray(c column, value any) (any, error) {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return value, nil
+	}
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+		return value, nil
+	}
+
+	element := column{typ: c.element}
+	elements := make([]any, v.Len())
+	for i := range elements {
+		e, err := encode(element, v.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		if t, ok := e.(time.Time); ok {
+			e = t.Format(time.RFC3339Nano)
+		}
+		elements[i] = e
+	}
+
+	return pq.GenericArray{A: elements}.Value()
+}
+
+func encodeRange(r Range) string {
+	if r.Bounds == "" {
+		r.Bounds = "[)"
+	}
+	return fmt.Sprintf(
+		"%c%v,%v%c",
+		r.Bounds[0], literal(r.Lower), literal(r.Upper), r.Bounds[len(r.Bounds)-1],
+	)
+}
+
+func encodeComposite(c column, value any) (any, error) {
+	fields := make(map[string]any)
+
+	v := reflect.Indirect(reflect.ValueOf(value))
+	switch v.Kind() {
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			fields[normalizeField(fmt.Sprint(key.Interface()))] = v.MapIndex(key).Interface()
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := field.Name
+			if tag, ok := field.Tag.Lookup("db"); ok {
+				name = tag
+			}
+			fields[normalizeField(name)] = v.Field(i).Interface()
+		}
+	default:
+		return value, nil
+	}
+
+	elements := make([]string, len(c.fields))
+	for i, name := range c.fields {
+		elements[i] = literal(fields[normalizeField(name)])
+	}
+
+	return fmt.Sprintf("(%v)", strings.Join(elements, ",")), nil
+}
+
+// Render an element of a range or composite literal.
+func literal(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		value = v.Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		value = v.String()
+	}
+	text := fmt.Sprint(value)
+	text = strings.ReplaceAll(text, `\`, `\\`)
+	text = strings.ReplaceAll(text, `"`, `\"`)
+	return `"` + text + `"`
+}
+
+func normalizeField(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
@@ -0,0 +1,62 @@
+package dumbo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/thebearingedge/dumbo/internal/dumbotest"
+)
+
+func TestEncodingRecords(t *testing.T) {
+	db := dumbotest.RequireDB(t)
+
+	seeder := New()
+
+	t.Run("encoding values by column type", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		type dimensions struct {
+			Width  int
+			Height int
+		}
+
+		gadget := seeder.InsertOne(t, tx, "gadget", Record{
+			"specs":    map[string]any{"color": "red"},
+			"tags":     []string{"go", "sql"},
+			"ratings":  []int{4, 5},
+			"mood":     "happy",
+			"size":     dimensions{Width: 3, Height: 4},
+			"warranty": Range{Lower: 1, Upper: 5},
+			"manuals":  []any{map[string]any{"lang": "en"}},
+		})
+
+		assert.Equal(t, map[string]any{"color": "red"}, gadget["specs"])
+		assert.Equal(t, []string{"go", "sql"}, gadget["tags"])
+		assert.Equal(t, []int64{4, 5}, gadget["ratings"])
+
+		assert.Equal(t, "happy", gadget["mood"])
+		assert.Equal(t, "(3,4)", gadget["size"])
+		assert.Equal(t, "[1,5)", gadget["warranty"])
+		assert.Equal(t, []any{map[string]any{"lang": "en"}}, gadget["manuals"])
+	})
+}
+
+func TestEncodingLiterals(t *testing.T) {
+	composite := column{category: "C", fields: []string{"width", "height", "label"}}
+
+	size, err := encode(composite, Record{"width": 3, "label": `a "b"`})
+	assert.NoError(t, err)
+	assert.Equal(t, `("3",,"a \"b\"")`, size)
+
+	assert.Equal(t, `["2023-01-01",)`, encodeRange(Range{Lower: "2023-01-01"}))
+	assert.Equal(t, `("1","5"]`, encodeRange(Range{Lower: 1, Upper: 5, Bounds: "(]"}))
+
+	tags, err := encode(column{category: "A", element: "text"}, []string{"go", "sql"})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"go","sql"}`, tags)
+
+	specs, err := encode(column{typ: "jsonb"}, []int{1, 2})
+	assert.NoError(t, err)
+	assert.Equal(t, `[1,2]`, specs)
+}
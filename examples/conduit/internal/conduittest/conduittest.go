@@ -57,7 +57,7 @@ func RequireSavepoint(t *testing.T, tx *postgres.Tx) *postgres.Tx {
 var Seeder dumbo.Dumbo = dumbo.New(
 	dumbo.Factory{
 		Table: "users",
-		NewRecord: func() dumbo.Record {
+		NewRecord: func(dumbo.Draw) dumbo.Record {
 			return dumbo.Record{
 				"username": faker.Username(),
 				"email":    faker.Email(),
@@ -75,7 +75,7 @@ var Seeder dumbo.Dumbo = dumbo.New(
 	},
 	dumbo.Factory{
 		Table: "articles",
-		NewRecord: func() dumbo.Record {
+		NewRecord: func(dumbo.Draw) dumbo.Record {
 			title := faker.Sentence()
 			whitespace := regexp.MustCompile(`[^A-Za-z0-9]`)
 			return dumbo.Record{
@@ -93,5 +93,6 @@ var Seeder dumbo.Dumbo = dumbo.New(
 				return fmt.Sprint(r["title"])
 			},
 		},
+		Timestamps: []string{"created_at", "updated_at"},
 	},
 )
@@ -0,0 +1,62 @@
+package dumbo
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Select the rows of the table that match, in primary key order.
+func (d Dumbo) FindWhere(t testing.TB, db DB, table string, match Record) []Record {
+	t.Helper()
+
+	described, err := d.catalog.table(db, table)
+	require.NoError(t, err, fmt.Sprintf("describing table %q", table))
+
+	order := ""
+	if len(described.primaryKey) > 0 {
+		order = fmt.Sprintf("\n\t\t order by %v", selection(described.primaryKey))
+	}
+
+	conditions, values := d.where(t, db, table, match, nil)
+
+	return d.FetchMany(t, db, fmt.Sprintf(`
+		select *
+		  from %q%v%v
+	`, table, conditions, order), values...)
+}
+
+// Count the rows of the table that match.
+func (d Dumbo) Count(t testing.TB, db DB, table string, match Record) int {
+	t.Helper()
+
+	conditions, values := d.where(t, db, table, match, nil)
+
+	var count int
+	err := d.scanOne(t, db, []any{&count}, fmt.Sprintf(`
+		select count(*)
+		  from %q%v
+	`, table, conditions), values...)
+	require.NoError(t, err, fmt.Sprintf("counting rows of table %q", table))
+
+	return count
+}
+
+// Check whether any row of the table matches.
+func (d Dumbo) Exists(t testing.TB, db DB, table string, match Record) bool {
+	t.Helper()
+
+	conditions, values := d.where(t, db, table, match, nil)
+
+	var exists bool
+	err := d.scanOne(t, db, []any{&exists}, fmt.Sprintf(`
+		select exists (
+		  select 1
+		    from %q%v
+		)
+	`, table, conditions), values...)
+	require.NoError(t, err, fmt.Sprintf("checking rows of table %q exist", table))
+
+	return exists
+}
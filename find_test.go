@@ -0,0 +1,86 @@
+package dumbo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/thebearingedge/dumbo/internal/dumbotest"
+)
+
+func TestFindingRecords(t *testing.T) {
+	db := dumbotest.RequireDB(t)
+
+	seeder := New()
+
+	tx := dumbotest.RequireBegin(t, db)
+
+	users := seeder.SeedMany(t, tx, "user", []Record{
+		{"username": "gopher"},
+		{"username": "rustacean"},
+		{"username": "pythonista"},
+	})
+	gopher, rustacean, pythonista := users[0], users[1], users[2]
+
+	root := seeder.InsertOne(t, tx, "category", Record{"name": "root"})
+	leaf := seeder.InsertOne(t, tx, "category", Record{"name": "leaf", "parent_id": root["id"]})
+
+	t.Run("finding rows by equality", func(t *testing.T) {
+		found := seeder.FindWhere(t, tx, "user", Record{"username": "gopher"})
+
+		assert.Equal(t, []Record{gopher}, found)
+	})
+
+	t.Run("finding rows by condition", func(t *testing.T) {
+		assert.Equal(t, []Record{root}, seeder.FindWhere(t, tx, "category", Record{"parent_id": IsNull}))
+		assert.Equal(t, []Record{leaf}, seeder.FindWhere(t, tx, "category", Record{"parent_id": IsNotNull}))
+		assert.Equal(t, []Record{rustacean, pythonista}, seeder.FindWhere(t, tx, "user", Record{"id": Gt(gopher["id"])}))
+		assert.Equal(t, []Record{gopher, rustacean}, seeder.FindWhere(t, tx, "user", Record{"id": Lte(rustacean["id"])}))
+		assert.Equal(t, []Record{gopher}, seeder.FindWhere(t, tx, "user", Record{"username": Like("go%")}))
+		assert.Empty(t, seeder.FindWhere(t, tx, "user", Record{"username": In()}))
+	})
+
+	t.Run("counting rows", func(t *testing.T) {
+		assert.Equal(t, 3, seeder.Count(t, tx, "user", Record{}))
+		assert.Equal(t, 2, seeder.Count(t, tx, "user", Record{"username": In("gopher", "pythonista")}))
+		assert.Equal(t, 2, seeder.Count(t, tx, "user", Record{"username": Ne("gopher")}))
+		assert.Equal(t, 1, seeder.Count(t, tx, "user", Record{"id": Gte(pythonista["id"])}))
+		assert.Equal(t, 1, seeder.Count(t, tx, "user", Record{"id": Lt(rustacean["id"])}))
+	})
+
+	t.Run("checking rows exist", func(t *testing.T) {
+		assert.True(t, seeder.Exists(t, tx, "user", Record{"username": "gopher"}))
+		assert.False(t, seeder.Exists(t, tx, "user", Record{"username": "ocaml"}))
+	})
+
+	t.Run("counting regardless of decoders", func(t *testing.T) {
+		config := Defaults()
+		config.Decoders["INT8"] = DecodeText
+		config.Decoders["BOOL"] = DecodeText
+
+		decoding := NewWithConfig(config)
+
+		assert.Equal(t, 3, decoding.Count(t, tx, "user", Record{}))
+		assert.True(t, decoding.Exists(t, tx, "user", Record{"username": "gopher"}))
+	})
+}
+
+func TestEncodingConditions(t *testing.T) {
+	gadget := &table{
+		name: "gadget",
+		columns: []column{
+			{name: "tags", category: "A", element: "text"},
+			{name: "specs", typ: "jsonb"},
+		},
+	}
+
+	conditions, values, err := where(gadget, Record{
+		"tags":  []string{"go"},
+		"specs": In(map[string]any{"color": "red"}),
+		"name":  "gopher",
+	}, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "\n\t\t where \"name\" = $1\n\t\t   and \"specs\" in ($2)\n\t\t   and \"tags\" = $3", conditions)
+	assert.Equal(t, []any{"gopher", `{"color":"red"}`, `{"go"}`}, values)
+}
@@ -0,0 +1,70 @@
+package dumbo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// A byteSource draws random numbers from fuzzer input, eight bytes at a time,
+// and once the input runs out from a source seeded with it, so that the short
+// inputs the fuzzer minimizes to still generate distinct values.
+type byteSource struct {
+	data []byte
+	rest rand.Source64
+}
+
+// A random source that replays the bytes, so the same input always makes the
+// same choices and generates the same values.
+func NewByteSource(data []byte) rand.Source {
+	h := fnv.New64a()
+	_, _ = h.Write(data)
+	rest := rand.NewSource(int64(h.Sum64())).(rand.Source64)
+	return &byteSource{data: data, rest: rest}
+}
+
+func (s *byteSource) Int63() int64 {
+	return int64(s.Uint64() & (1<<63 - 1))
+}
+
+func (s *byteSource) Uint64() uint64 {
+	if len(s.data) == 0 {
+		return s.rest.Uint64()
+	}
+	var chunk [8]byte
+	n := copy(chunk[:], s.data)
+	s.data = s.data[n:]
+	return binary.BigEndian.Uint64(chunk[:])
+}
+
+func (s *byteSource) Seed(int64) {}
+
+// Run f with the test's random source drawing from the fuzzer input, so the
+// records that f inserts are built from it, e.g. in a target of f.Fuzz.
+// Records are inserted in a scope of their own, like Run, and in a savepoint
+// that is rolled back and released when the test is done, so db must be a
+// transaction.
+func (d *Dumbo) Fuzz(t testing.TB, db DB, data []byte, f func(t testing.TB, d *Dumbo)) {
+	t.Helper()
+
+	savepoint := fmt.Sprintf(`"dumbo_fuzz_%v"`, len(d.runs))
+
+	_, err := d.exec(t, db, "savepoint "+savepoint)
+	require.NoError(t, err, "creating savepoint for fuzz input")
+	t.Cleanup(func() {
+		_, err := d.exec(t, db, "rollback to savepoint "+savepoint)
+		require.NoError(t, err, "rolling back savepoint for fuzz input")
+		_, err = d.exec(t, db, "release savepoint "+savepoint)
+		require.NoError(t, err, "releasing savepoint for fuzz input")
+	})
+
+	d.random.mu.Lock()
+	d.random.install(t, NewByteSource(data))
+	d.random.mu.Unlock()
+
+	d.Run(t, func(d *Dumbo) { f(t, d) })
+}
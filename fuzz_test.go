@@ -0,0 +1,52 @@
+package dumbo
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/go-faker/faker/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/thebearingedge/dumbo/internal/dumbotest"
+)
+
+func TestByteSource(t *testing.T) {
+	draw := func(data []byte) []int {
+		r := rand.New(NewByteSource(data))
+		return []int{r.Intn(100), r.Intn(100), r.Intn(100)}
+	}
+
+	data := []byte("gophers dig tunnels under the database")
+
+	assert.Equal(t, draw(data), draw(data))
+	assert.NotEqual(t, draw(data), draw([]byte("other input")))
+	assert.Equal(t, draw(nil), draw(nil))
+}
+
+func FuzzInsertingUsers(f *testing.F) {
+	db := dumbotest.RequireDB(f)
+	tx := dumbotest.RequireBegin(f, db)
+
+	seeder := New(Factory{
+		Table: "user",
+		NewRecord: func(Draw) Record {
+			return Record{"username": faker.Username() + faker.Word()}
+		},
+		UniqueBy: []Indexer{
+			func(r Record) string { return r["username"].(string) },
+		},
+	})
+
+	f.Add([]byte("gopher"))
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		seeder.Fuzz(t, tx, data, func(t testing.TB, d *Dumbo) {
+			n := 1 + d.Rand(t).Intn(5)
+			users := d.InsertMany(t, tx, "user", make([]Record, n))
+
+			assert.Equal(t, n, d.Count(t, tx, "user", Record{}))
+			assert.Len(t, users, n)
+		})
+	})
+}
@@ -0,0 +1,237 @@
+// Package gen generates column values that fit the table they are inserted
+// into. Generators see the described column, so strings stay within
+// varchar(n), numbers within check constraints and enums within their labels.
+package gen
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// A Column is what the database says about the column being generated.
+type Column struct {
+	Name     string
+	Type     string
+	Nullable bool
+	// Length is the maximum number of characters, 0 when unbounded.
+	Length int
+	// Precision and Scale are the digits of numeric(p, s) in all and after
+	// the point, 0 when unbounded.
+	Precision int
+	Scale     int
+	// Min and Max are parsed from check constraints, nil when unbounded.
+	Min *Bound
+	Max *Bound
+	// Labels are the values of an enum type in order.
+	Labels []string
+}
+
+// A Bound is one end of the range a check constraint allows.
+type Bound struct {
+	Value     float64
+	Exclusive bool
+}
+
+// A Field is the column to generate a value for, the test's random source and
+// the time on the test's clock, see Dumbo.Now.
+type Field struct {
+	Column Column
+	Rand   *rand.Rand
+	Now    time.Time
+}
+
+// A Generator returns a value for the field.
+type Generator func(f Field) any
+
+// A Choice is a generator picked with the given weight.
+type Choice struct {
+	Weight    int
+	Generator Generator
+}
+
+// Always generate the value.
+func Const(value any) Generator {
+	return func(Field) any {
+		return value
+	}
+}
+
+// Generate one of the values with equal probability.
+func Pick(values ...any) Generator {
+	return func(f Field) any {
+		return values[f.Rand.Intn(len(values))]
+	}
+}
+
+// Generate a value from one of the generators with equal probability.
+func OneOf(generators ...Generator) Generator {
+	return func(f Field) any {
+		return generators[f.Rand.Intn(len(generators))](f)
+	}
+}
+
+// Generate a value from one of the choices in proportion to its weight.
+func Weighted(choices ...Choice) Generator {
+	total := 0
+	for _, choice := range choices {
+		if choice.Weight > 0 {
+			total += choice.Weight
+		}
+	}
+	return func(f Field) any {
+		if total == 0 {
+			panic("gen: weighted choices have no positive weight")
+		}
+		n := f.Rand.Intn(total)
+		for _, choice := range choices {
+			if choice.Weight <= 0 {
+				continue
+			}
+			if n < choice.Weight {
+				return choice.Generator(f)
+			}
+			n -= choice.Weight
+		}
+		panic("unreachable")
+	}
+}
+
+// Generate null with probability p, otherwise a value of the generator.
+// Columns that are not nullable are never null.
+func Nullable(p float64, generator Generator) Generator {
+	return func(f Field) any {
+		if f.Column.Nullable && f.Rand.Float64() < p {
+			return nil
+		}
+		return generator(f)
+	}
+}
+
+const letters = "abcdefghijklmnopqrstuvwxyz"
+
+// Generate lowercase letters between min and max long, no longer than the
+// column allows.
+func String(min, max int) Generator {
+	return func(f Field) any {
+		lo, hi := min, max
+		if f.Column.Length > 0 && hi > f.Column.Length {
+			hi = f.Column.Length
+		}
+		if lo > hi {
+			lo = hi
+		}
+		text := make([]byte, lo+f.Rand.Intn(hi-lo+1))
+		for i := range text {
+			text[i] = letters[f.Rand.Intn(len(letters))]
+		}
+		return string(text)
+	}
+}
+
+// Generate an integer between min and max inclusive, narrowed to the range
+// the column's check constraints allow.
+func Int(min, max int64) Generator {
+	return func(f Field) any {
+		lo, hi := min, max
+		if b := f.Column.Min; b != nil {
+			bound := int64(math.Ceil(b.Value))
+			if b.Exclusive && float64(bound) == b.Value {
+				bound++
+			}
+			if bound > lo {
+				lo = bound
+			}
+		}
+		if b := f.Column.Max; b != nil {
+			bound := int64(math.Floor(b.Value))
+			if b.Exclusive && float64(bound) == b.Value {
+				bound--
+			}
+			if bound < hi {
+				hi = bound
+			}
+		}
+		if lo > hi {
+			panic(fmt.Sprintf("gen: no integer between %v and %v fits column %q", min, max, f.Column.Name))
+		}
+		return lo + f.Rand.Int63n(hi-lo+1)
+	}
+}
+
+// Generate a float between min and max, narrowed to the range the column's
+// check constraints and numeric precision allow, and rounded to its scale.
+func Float(min, max float64) Generator {
+	return func(f Field) any {
+		lo, hi := min, max
+		if p, s := f.Column.Precision, f.Column.Scale; p > 0 {
+			limit := math.Pow10(p-s) - math.Pow10(-s)
+			lo, hi = math.Max(lo, -limit), math.Min(hi, limit)
+		}
+		if b := f.Column.Min; b != nil && b.Value >= lo {
+			lo = b.Value
+			if b.Exclusive {
+				lo = math.Nextafter(lo, math.Inf(1))
+			}
+		}
+		if b := f.Column.Max; b != nil && b.Value <= hi {
+			hi = b.Value
+			if b.Exclusive {
+				hi = math.Nextafter(hi, math.Inf(-1))
+			}
+		}
+		if lo > hi {
+			panic(fmt.Sprintf("gen: no float between %v and %v fits column %q", min, max, f.Column.Name))
+		}
+		n := lo + f.Rand.Float64()*(hi-lo)
+		if f.Column.Precision > 0 {
+			unit := math.Pow10(f.Column.Scale)
+			n = math.Round(n*unit) / unit
+			if n > hi {
+				n = math.Floor(hi*unit) / unit
+			}
+			if n < lo {
+				n = math.Ceil(lo*unit) / unit
+			}
+		}
+		return n
+	}
+}
+
+// Generate a time between from and to, at the microsecond precision of
+// Postgres timestamps.
+func Time(from, to time.Time) Generator {
+	return func(f Field) any {
+		window := to.Sub(from)
+		if window <= 0 {
+			return from.Truncate(time.Microsecond)
+		}
+		offset := time.Duration(f.Rand.Int63n(int64(window)))
+		return from.Add(offset).Truncate(time.Microsecond)
+	}
+}
+
+// Generate the time on the test's clock.
+func Now() Generator {
+	return func(f Field) any {
+		return f.Now.Truncate(time.Microsecond)
+	}
+}
+
+// Generate a time between min and max before the time on the test's clock.
+func Ago(min, max time.Duration) Generator {
+	return func(f Field) any {
+		return Time(f.Now.Add(-max), f.Now.Add(-min))(f)
+	}
+}
+
+// Generate one of the labels of the column's enum type.
+func Enum() Generator {
+	return func(f Field) any {
+		if len(f.Column.Labels) == 0 {
+			panic(fmt.Sprintf("gen: column %q is not an enum", f.Column.Name))
+		}
+		return f.Column.Labels[f.Rand.Intn(len(f.Column.Labels))]
+	}
+}
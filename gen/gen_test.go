@@ -0,0 +1,90 @@
+package gen
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func field(c Column) Field {
+	return Field{Column: c, Rand: rand.New(rand.NewSource(1))}
+}
+
+func TestGenerators(t *testing.T) {
+	t.Run("bounding strings by length", func(t *testing.T) {
+		f := field(Column{Length: 4})
+		for i := 0; i < 100; i++ {
+			text := String(2, 20)(f).(string)
+			assert.GreaterOrEqual(t, len(text), 2)
+			assert.LessOrEqual(t, len(text), 4)
+		}
+	})
+
+	t.Run("bounding integers by checks", func(t *testing.T) {
+		f := field(Column{Min: &Bound{Value: 0, Exclusive: true}, Max: &Bound{Value: 3}})
+		seen := make(map[any]bool)
+		for i := 0; i < 100; i++ {
+			seen[Int(-10, 10)(f)] = true
+		}
+		assert.Equal(t, map[any]bool{int64(1): true, int64(2): true, int64(3): true}, seen)
+	})
+
+	t.Run("bounding floats by checks", func(t *testing.T) {
+		f := field(Column{Min: &Bound{Value: 1}, Max: &Bound{Value: 2, Exclusive: true}})
+		for i := 0; i < 100; i++ {
+			n := Float(0, 100)(f).(float64)
+			assert.GreaterOrEqual(t, n, 1.0)
+			assert.Less(t, n, 2.0)
+		}
+	})
+
+	t.Run("bounding floats by numeric precision", func(t *testing.T) {
+		f := field(Column{Precision: 3, Scale: 1})
+		for i := 0; i < 100; i++ {
+			n := Float(0, 1000)(f).(float64)
+			assert.GreaterOrEqual(t, n, 0.0)
+			assert.LessOrEqual(t, n, 99.9)
+			assert.Equal(t, n, math.Round(n*10)/10)
+		}
+	})
+
+	t.Run("failing when no integer fits", func(t *testing.T) {
+		f := field(Column{Name: "quantity", Min: &Bound{Value: 5}})
+		assert.PanicsWithValue(t, `gen: no integer between 0 and 4 fits column "quantity"`, func() {
+			Int(0, 4)(f)
+		})
+	})
+
+	t.Run("choosing by weight", func(t *testing.T) {
+		f := field(Column{})
+		generate := Weighted(Choice{Weight: 0, Generator: Const("never")}, Choice{Weight: 1, Generator: Const("always")})
+		for i := 0; i < 100; i++ {
+			assert.Equal(t, "always", generate(f))
+		}
+	})
+
+	t.Run("generating nulls only for nullable columns", func(t *testing.T) {
+		assert.Nil(t, Nullable(1, Const(1))(field(Column{Nullable: true})))
+		assert.Equal(t, 1, Nullable(1, Const(1))(field(Column{})))
+	})
+
+	t.Run("generating times in a window", func(t *testing.T) {
+		f := field(Column{})
+		from := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+		to := from.Add(time.Hour)
+		for i := 0; i < 100; i++ {
+			at := Time(from, to)(f).(time.Time)
+			assert.WithinRange(t, at, from, to)
+			assert.Equal(t, at, at.Truncate(time.Microsecond))
+		}
+	})
+
+	t.Run("picking enum labels", func(t *testing.T) {
+		f := field(Column{Labels: []string{"happy", "sad"}})
+		assert.Contains(t, []any{"happy", "sad"}, Enum()(f))
+		assert.Panics(t, func() { Enum()(field(Column{Name: "mood"})) })
+	})
+}
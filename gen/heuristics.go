@@ -0,0 +1,116 @@
+package gen
+
+import (
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-faker/faker/v4"
+	"github.com/go-faker/faker/v4/pkg/options"
+)
+
+// A Heuristic suggests a generator for a column, or nil when it has none.
+type Heuristic func(c Column) Generator
+
+// Suggest the generator for columns whose name matches the pattern, as with
+// path.Match, e.g. "*_url".
+func Named(pattern string, generator Generator) Heuristic {
+	return func(c Column) Generator {
+		if matched, _ := path.Match(pattern, c.Name); matched {
+			return generator
+		}
+		return nil
+	}
+}
+
+// Suggest the generator for columns of the types, e.g. "timestamptz".
+func Typed(generator Generator, types ...string) Heuristic {
+	return func(c Column) Generator {
+		for _, typ := range types {
+			if c.Type == typ {
+				return generator
+			}
+		}
+		return nil
+	}
+}
+
+// The generator suggested by the first heuristic that has one, or nil.
+func Infer(heuristics []Heuristic, c Column) Generator {
+	for _, heuristic := range heuristics {
+		if generator := heuristic(c); generator != nil {
+			return generator
+		}
+	}
+	return nil
+}
+
+// Timestamps are generated in the year before the time on the test's clock.
+const year = 365 * 24 * time.Hour
+
+// The heuristics for common column names, followed by fallbacks for common
+// types. Text from faker is cut to the length of the column. Prepend
+// heuristics to override them or append to extend them.
+func DefaultHeuristics() []Heuristic {
+	return []Heuristic{
+		Named("email", Faked(faker.Email)),
+		Named("*_email", Faked(faker.Email)),
+		Named("username", Faked(faker.Username)),
+		Named("url", Faked(faker.URL)),
+		Named("*_url", Faked(faker.URL)),
+		Named("first_name", Faked(faker.FirstName)),
+		Named("last_name", Faked(faker.LastName)),
+		Named("name", Faked(faker.Name)),
+		Named("phone", Faked(faker.Phonenumber)),
+		Named("title", Faked(func(...options.OptionFunc) string {
+			return strings.TrimSuffix(faker.Sentence(), ".")
+		})),
+		Named("slug", Slug()),
+		Named("bio", Faked(faker.Paragraph)),
+		Named("body", Faked(faker.Paragraph)),
+		Named("description", Faked(faker.Paragraph)),
+		Named("*_at", Ago(0, year)),
+		func(c Column) Generator {
+			if len(c.Labels) > 0 {
+				return Enum()
+			}
+			return nil
+		},
+		Typed(String(8, 16), "text", "varchar", "bpchar"),
+		Typed(Int(0, 1000), "int2", "int4", "int8"),
+		Typed(Float(0, 1000), "numeric", "float4", "float8"),
+		Typed(Pick(true, false), "bool"),
+		Typed(Ago(0, year), "timestamptz", "timestamp", "date"),
+		Typed(Faked(faker.UUIDHyphenated), "uuid"),
+		Typed(Const("{}"), "json", "jsonb"),
+	}
+}
+
+// Generate text with a faker function, cut to the length of the column.
+// Faker draws from the random source of the test, see Dumbo.Rand.
+func Faked(fake func(...options.OptionFunc) string) Generator {
+	return func(f Field) any {
+		return truncate(fake(), f.Column.Length)
+	}
+}
+
+// Generate lowercase words joined by hyphens, no longer than the column allows.
+func Slug() Generator {
+	return func(f Field) any {
+		words := make([]string, 2+f.Rand.Intn(3))
+		for i := range words {
+			words[i] = String(3, 8)(Field{Rand: f.Rand}).(string)
+		}
+		return strings.TrimRight(truncate(strings.Join(words, "-"), f.Column.Length), "-")
+	}
+}
+
+// Cut text to the length of a column, which counts characters rather than
+// bytes, or leave it whole when the column has no length.
+func truncate(text string, length int) string {
+	if length > 0 && utf8.RuneCountInString(text) > length {
+		return string([]rune(text)[:length])
+	}
+	return text
+}
@@ -0,0 +1,51 @@
+package gen
+
+import (
+	"testing"
+
+	"github.com/go-faker/faker/v4/pkg/options"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeuristics(t *testing.T) {
+	t.Run("matching column names", func(t *testing.T) {
+		heuristic := Named("*_url", Const("https://example.com"))
+
+		assert.NotNil(t, heuristic(Column{Name: "avatar_url"}))
+		assert.Nil(t, heuristic(Column{Name: "url_count"}))
+	})
+
+	t.Run("preferring earlier heuristics", func(t *testing.T) {
+		heuristics := append([]Heuristic{Named("email", Const("gopher@example.com"))}, DefaultHeuristics()...)
+
+		generate := Infer(heuristics, Column{Name: "email", Type: "text"})
+
+		assert.Equal(t, "gopher@example.com", generate(field(Column{})))
+	})
+
+	t.Run("falling back to column types", func(t *testing.T) {
+		generate := Infer(DefaultHeuristics(), Column{Name: "visits", Type: "int4"})
+
+		assert.IsType(t, int64(0), generate(field(Column{})))
+	})
+
+	t.Run("cutting text to the column length", func(t *testing.T) {
+		c := Column{Name: "slug", Type: "varchar", Length: 5}
+
+		generate := Infer(DefaultHeuristics(), c)
+
+		assert.LessOrEqual(t, len(generate(field(c)).(string)), 5)
+	})
+
+	t.Run("cutting text by characters", func(t *testing.T) {
+		c := Column{Name: "motto", Type: "varchar", Length: 4}
+
+		generate := Faked(func(...options.OptionFunc) string { return "¡olé gophers!" })
+
+		assert.Equal(t, "¡olé", generate(field(c)))
+	})
+
+	t.Run("suggesting nothing for unknown columns", func(t *testing.T) {
+		assert.Nil(t, Infer(DefaultHeuristics(), Column{Name: "shape", Type: "polygon"}))
+	})
+}
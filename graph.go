@@ -0,0 +1,253 @@
+package dumbo
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// A Node describes a row to insert along with the rows that reference it.
+//
+// Children are inserted after the node with their foreign key to the node
+// filled in. Via names the child's foreign key column when the child table
+// references the node's table more than once.
+//
+// A Node or *Node used as a value in Record is inserted before the record and
+// replaced by its referenced key. Share a *Node to reference the same row
+// from several places in the graph.
+type Node struct {
+	Table    string
+	Record   Record
+	Via      string
+	Children []Node
+}
+
+// The rows inserted for a graph of nodes.
+type Graph struct {
+	tables map[string][]Record
+	nodes  map[*Node]Record
+}
+
+// The i-th record declared for the table.
+func (g Graph) Get(table string, i int) Record {
+	return g.tables[table][i]
+}
+
+// Every record declared for the table.
+func (g Graph) All(table string) []Record {
+	return g.tables[table]
+}
+
+// The record inserted for a shared node.
+func (g Graph) Of(node *Node) Record {
+	return g.nodes[node]
+}
+
+type edge struct {
+	fk     foreignKey
+	parent *Node
+}
+
+type vertex struct {
+	node    *Node
+	partial Record
+	edges   []edge
+}
+
+// Add a graph of records, parents first, filling in their foreign keys.
+func (d *Dumbo) InsertGraph(t testing.TB, db DB, nodes ...Node) Graph {
+	t.Helper()
+
+	declared, err := d.plan(db, nodes)
+	require.NoError(t, err, "planning graph insert")
+
+	ordered, err := sortVertices(declared)
+	require.NoError(t, err, "planning graph insert")
+
+	inserted := make(map[*Node]Record, len(ordered))
+	for _, v := range ordered {
+		partial := make(Record, len(v.partial))
+		for column, value := range v.partial {
+			partial[column] = value
+		}
+		for _, e := range v.edges {
+			parent := inserted[e.parent]
+			for i, column := range e.fk.columns {
+				key, ok := parent[e.fk.keys[i]]
+				require.True(t, ok, fmt.Sprintf(
+					"inserted %q record has no column %q for foreign key %q",
+					e.parent.Table, e.fk.keys[i], e.fk.name,
+				))
+				partial[column] = key
+			}
+		}
+		inserted[v.node] = d.InsertOne(t, db, v.node.Table, partial)
+	}
+
+	graph := Graph{
+		tables: make(map[string][]Record),
+		nodes:  inserted,
+	}
+	for _, v := range declared {
+		graph.tables[v.node.Table] = append(graph.tables[v.node.Table], inserted[v.node])
+	}
+
+	return graph
+}
+
+// Collect the nodes in declaration order along with their foreign keys.
+func (d *Dumbo) plan(db DB, roots []Node) ([]*vertex, error) {
+	declared := make([]*vertex, 0, len(roots))
+	visited := make(map[*Node]*vertex)
+
+	var visit func(node *Node) (*vertex, error)
+	visit = func(node *Node) (*vertex, error) {
+		if v, ok := visited[node]; ok {
+			return v, nil
+		}
+
+		described, err := d.catalog.table(db, node.Table)
+		if err != nil {
+			return nil, err
+		}
+
+		v := &vertex{node: node, partial: make(Record, len(node.Record))}
+		visited[node] = v
+		declared = append(declared, v)
+
+		columns := make([]string, 0, len(node.Record))
+		for column := range node.Record {
+			columns = append(columns, column)
+		}
+		sort.Strings(columns)
+
+		for _, column := range columns {
+			var parent *Node
+			switch value := node.Record[column].(type) {
+			case *Node:
+				parent = value
+			case Node:
+				parent = &value
+			default:
+				v.partial[column] = value
+				continue
+			}
+			fk, ok := described.foreignKey(column)
+			if !ok {
+				return nil, fmt.Errorf("column %q of table %q is not a foreign key", column, node.Table)
+			}
+			if fk.references != parent.Table {
+				return nil, fmt.Errorf(
+					"column %q of table %q references table %q, not %q",
+					column, node.Table, fk.references, parent.Table,
+				)
+			}
+			if _, err := visit(parent); err != nil {
+				return nil, err
+			}
+			v.edges = append(v.edges, edge{fk: fk, parent: parent})
+		}
+
+		for i := range node.Children {
+			child := &node.Children[i]
+			cv, err := visit(child)
+			if err != nil {
+				return nil, err
+			}
+			fk, err := d.childKey(db, child.Table, child.Via, node.Table)
+			if err != nil {
+				return nil, err
+			}
+			cv.edges = append(cv.edges, edge{fk: fk, parent: node})
+		}
+
+		return v, nil
+	}
+
+	for i := range roots {
+		if _, err := visit(&roots[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return declared, nil
+}
+
+// Find the foreign key of the child that references the parent table.
+func (d *Dumbo) childKey(db DB, child string, via string, parent string) (foreignKey, error) {
+	described, err := d.catalog.table(db, child)
+	if err != nil {
+		return foreignKey{}, err
+	}
+
+	if via != "" {
+		fk, ok := described.foreignKey(via)
+		if !ok || fk.references != parent {
+			return foreignKey{}, fmt.Errorf(
+				"column %q of table %q is not a foreign key to table %q",
+				via, child, parent,
+			)
+		}
+		return fk, nil
+	}
+
+	fks := described.referencing(parent)
+	switch len(fks) {
+	case 0:
+		return foreignKey{}, fmt.Errorf("table %q has no foreign key to table %q", child, parent)
+	case 1:
+		return fks[0], nil
+	default:
+		return foreignKey{}, fmt.Errorf(
+			"table %q has %v foreign keys to table %q, set Via to choose one",
+			child, len(fks), parent,
+		)
+	}
+}
+
+// Order the vertices so that every parent comes before its children.
+func sortVertices(declared []*vertex) ([]*vertex, error) {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+
+	byNode := make(map[*Node]*vertex, len(declared))
+	for _, v := range declared {
+		byNode[v.node] = v
+	}
+
+	state := make(map[*vertex]int, len(declared))
+	ordered := make([]*vertex, 0, len(declared))
+
+	var visit func(v *vertex) error
+	visit = func(v *vertex) error {
+		switch state[v] {
+		case visiting:
+			return errors.New("graph contains a foreign key cycle")
+		case done:
+			return nil
+		}
+		state[v] = visiting
+		for _, e := range v.edges {
+			if err := visit(byNode[e.parent]); err != nil {
+				return err
+			}
+		}
+		state[v] = done
+		ordered = append(ordered, v)
+		return nil
+	}
+
+	for _, v := range declared {
+		if err := visit(v); err != nil {
+			return nil, err
+		}
+	}
+
+	return ordered, nil
+}
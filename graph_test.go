@@ -0,0 +1,99 @@
+package dumbo
+
+import (
+	"testing"
+
+	"github.com/go-faker/faker/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/thebearingedge/dumbo/internal/dumbotest"
+)
+
+func TestInsertingGraphs(t *testing.T) {
+	db := dumbotest.RequireDB(t)
+
+	seeder := New(
+		Factory{
+			Table: "user",
+			NewRecord: func(Draw) Record {
+				return Record{
+					"username": faker.Username(),
+				}
+			},
+		},
+		Factory{
+			Table: "post",
+			NewRecord: func(Draw) Record {
+				return Record{
+					"title": faker.Sentence(),
+				}
+			},
+		},
+	)
+
+	t.Run("filling foreign keys of children", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		graph := seeder.InsertGraph(t, tx, Node{
+			Table:  "user",
+			Record: Record{"username": "gopher"},
+			Children: []Node{
+				{Table: "post", Record: Record{"title": "first"}},
+				{Table: "post", Record: Record{"title": "second"}},
+			},
+		})
+
+		gopher := graph.Get("user", 0)
+
+		assert.Equal(t, "gopher", gopher["username"])
+		assert.Len(t, graph.All("post"), 2)
+		assert.Equal(t, "first", graph.Get("post", 0)["title"])
+		assert.Equal(t, gopher["id"], graph.Get("post", 0)["author_id"])
+		assert.Equal(t, "second", graph.Get("post", 1)["title"])
+		assert.Equal(t, gopher["id"], graph.Get("post", 1)["author_id"])
+	})
+
+	t.Run("inserting referenced parents first", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		author := &Node{Table: "user"}
+
+		graph := seeder.InsertGraph(t, tx, Node{
+			Table: "comment",
+			Record: Record{
+				"body":      "first!",
+				"author_id": author,
+				"post_id":   Node{Table: "post", Record: Record{"author_id": author}},
+			},
+		})
+
+		user := graph.Of(author)
+		post := graph.Get("post", 0)
+		comment := graph.Get("comment", 0)
+
+		assert.Len(t, graph.All("user"), 1)
+		assert.Equal(t, user["id"], post["author_id"])
+		assert.Equal(t, user["id"], comment["author_id"])
+		assert.Equal(t, post["id"], comment["post_id"])
+	})
+
+	t.Run("choosing among foreign keys to the same table", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		graph := seeder.InsertGraph(t, tx, Node{
+			Table: "user",
+			Children: []Node{
+				{
+					Table:  "follower",
+					Via:    "following_id",
+					Record: Record{"follower_id": Node{Table: "user"}},
+				},
+			},
+		})
+
+		followed, follower := graph.Get("user", 0), graph.Get("user", 1)
+
+		assert.Equal(t, followed["id"], graph.Get("follower", 0)["following_id"])
+		assert.Equal(t, follower["id"], graph.Get("follower", 0)["follower_id"])
+	})
+}
@@ -10,7 +10,7 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func RequireDB(t *testing.T) *sql.DB {
+func RequireDB(t testing.TB) *sql.DB {
 	t.Helper()
 	db, err := sql.Open("postgres", os.Getenv("DATABASE_URL"))
 	t.Cleanup(func() { require.NoError(t, db.Close()) })
@@ -18,7 +18,7 @@ func RequireDB(t *testing.T) *sql.DB {
 	return db
 }
 
-func RequireBegin(t *testing.T, db *sql.DB) *sql.Tx {
+func RequireBegin(t testing.TB, db *sql.DB) *sql.Tx {
 	t.Helper()
 	tx, err := db.Begin()
 	t.Cleanup(func() { require.NoError(t, tx.Rollback()) })
@@ -26,7 +26,7 @@ func RequireBegin(t *testing.T, db *sql.DB) *sql.Tx {
 	return tx
 }
 
-func RequireSavepoint(t *testing.T, tx *sql.Tx) *sql.Tx {
+func RequireSavepoint(t testing.TB, tx *sql.Tx) *sql.Tx {
 	t.Helper()
 	_, err := tx.Exec(fmt.Sprintf("savepoint %q", t.Name()))
 	t.Cleanup(func() {
@@ -0,0 +1,82 @@
+package dumbotest
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// A Recorder is a testing.TB that records failures and logs instead of
+// reporting them, for testing helpers that are meant to fail the test.
+type Recorder struct {
+	testing.TB
+	failed   bool
+	Errors   []string
+	Logs     []string
+	cleanups []func()
+}
+
+func (r *Recorder) Fail() {
+	r.failed = true
+}
+
+func (r *Recorder) Failed() bool {
+	return r.failed
+}
+
+func (r *Recorder) FailNow() {
+	r.Fail()
+	runtime.Goexit()
+}
+
+func (r *Recorder) Log(args ...any) {
+	r.Logs = append(r.Logs, strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+}
+
+func (r *Recorder) Logf(format string, args ...any) {
+	r.Logs = append(r.Logs, fmt.Sprintf(format, args...))
+}
+
+func (r *Recorder) Error(args ...any) {
+	r.Errors = append(r.Errors, strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+	r.Fail()
+}
+
+func (r *Recorder) Errorf(format string, args ...any) {
+	r.Errors = append(r.Errors, fmt.Sprintf(format, args...))
+	r.Fail()
+}
+
+func (r *Recorder) Fatal(args ...any) {
+	r.Error(args...)
+	runtime.Goexit()
+}
+
+func (r *Recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+	runtime.Goexit()
+}
+
+func (r *Recorder) Cleanup(f func()) {
+	r.cleanups = append(r.cleanups, f)
+}
+
+// Run f with a Recorder in place of t, on a goroutine of its own so that
+// FailNow stops only f, and run the cleanups f registered when it is done.
+func Record(t testing.TB, f func(t testing.TB)) *Recorder {
+	t.Helper()
+	r := &Recorder{TB: t}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() {
+			for i := len(r.cleanups) - 1; i >= 0; i-- {
+				r.cleanups[i]()
+			}
+		}()
+		f(r)
+	}()
+	<-done
+	return r
+}
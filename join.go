@@ -0,0 +1,146 @@
+package dumbo
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// A Join describes a many-to-many join table.
+//
+// From and To name a foreign key column of the join table for each side of
+// the link. When left blank, they are taken from the table's foreign keys in
+// column order.
+type Join struct {
+	Table string
+	From  string
+	To    string
+}
+
+// Link every record in from to every record in to through the join table.
+// Each pair can be linked once in the scope of a test, like the unique keys of
+// a factory.
+func (d *Dumbo) Link(t testing.TB, db DB, join Join, from, to []Record) []Record {
+	t.Helper()
+
+	described, err := d.catalog.table(db, join.Table)
+	require.NoError(t, err, fmt.Sprintf("describing join table %q", join.Table))
+
+	left, right, err := joinKeys(described, join)
+	require.NoError(t, err, fmt.Sprintf("describing join table %q", join.Table))
+
+	links := make([]Record, 0, len(from)*len(to))
+	for _, f := range from {
+		for _, r := range to {
+			link := make(Record, len(left.columns)+len(right.columns))
+			for _, side := range []struct {
+				fk     foreignKey
+				record Record
+			}{{left, f}, {right, r}} {
+				for i, column := range side.fk.columns {
+					key, ok := side.record[side.fk.keys[i]]
+					require.True(t, ok, fmt.Sprintf(
+						"linked record has no column %q for foreign key %q",
+						side.fk.keys[i], side.fk.name,
+					))
+					link[column] = key
+				}
+			}
+			links = append(links, link)
+		}
+	}
+
+	if len(links) == 0 {
+		return links
+	}
+
+	pair := indexColumns(described.primaryKey)
+	if len(described.primaryKey) == 0 {
+		pair = indexColumns(append(left.columns[:len(left.columns):len(left.columns)], right.columns...))
+	}
+
+	// the pairs are indexed after the indexers of the table's factory
+	position := len(d.factories[join.Table].UniqueBy)
+	linked := make(Index, len(links))
+	for _, link := range links {
+		key := pair(link)
+		_, duplicate := linked[key]
+		for _, run := range d.runs {
+			if indexes := run.indexes[join.Table]; position < len(indexes) {
+				if _, ok := indexes[position][key]; ok {
+					duplicate = true
+				}
+			}
+		}
+		if duplicate {
+			require.FailNow(t, fmt.Sprintf(
+				"duplicate link between %q %v and %q %v in join table %q",
+				left.references, indexColumns(left.columns)(link),
+				right.references, indexColumns(right.columns)(link),
+				join.Table,
+			))
+		}
+		linked[key] = struct{}{}
+	}
+
+	return d.insertMany(t, db, join.Table, []Indexer{pair}, links)
+}
+
+// Find the foreign keys for each side of the join.
+func joinKeys(described *table, join Join) (foreignKey, foreignKey, error) {
+	fks := make([]foreignKey, len(described.foreignKeys))
+	copy(fks, described.foreignKeys)
+
+	position := make(map[string]int, len(described.columns))
+	for i, column := range described.columns {
+		position[column.name] = i
+	}
+	sort.SliceStable(fks, func(i, j int) bool {
+		return position[fks[i].columns[0]] < position[fks[j].columns[0]]
+	})
+
+	pick := func(column string, skip string) (foreignKey, error) {
+		if column != "" {
+			fk, ok := described.foreignKey(column)
+			if !ok {
+				return foreignKey{}, fmt.Errorf("column %q of table %q is not a foreign key", column, described.name)
+			}
+			return fk, nil
+		}
+		for _, fk := range fks {
+			if fk.name != skip {
+				return fk, nil
+			}
+		}
+		return foreignKey{}, fmt.Errorf("table %q does not have two foreign keys", described.name)
+	}
+
+	if join.From == "" && join.To != "" {
+		right, err := pick(join.To, "")
+		if err != nil {
+			return foreignKey{}, foreignKey{}, err
+		}
+		left, err := pick("", right.name)
+		return left, right, err
+	}
+
+	left, err := pick(join.From, "")
+	if err != nil {
+		return foreignKey{}, foreignKey{}, err
+	}
+	right, err := pick(join.To, left.name)
+	return left, right, err
+}
+
+// Index records by the values of their columns.
+func indexColumns(columns []string) Indexer {
+	return func(r Record) string {
+		values := make([]any, len(columns))
+		for i, column := range columns {
+			values[i] = r[column]
+		}
+		return fmt.Sprint(values)
+	}
+}
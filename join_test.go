@@ -0,0 +1,102 @@
+package dumbo
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/thebearingedge/dumbo/internal/dumbotest"
+)
+
+func TestLinkingRecords(t *testing.T) {
+	db := dumbotest.RequireDB(t)
+
+	seeder := New()
+
+	t.Run("linking every pair of records", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		gopher := seeder.InsertOne(t, tx, "user", Record{"username": "gopher"})
+		posts := seeder.InsertMany(t, tx, "post", []Record{
+			{"author_id": gopher["id"], "title": "first"},
+			{"author_id": gopher["id"], "title": "second"},
+		})
+		tags := seeder.InsertMany(t, tx, "tag", []Record{
+			{"name": "go"},
+			{"name": "sql"},
+		})
+
+		links := seeder.Link(t, tx, Join{Table: "post_tag"}, posts, tags)
+
+		assert.Len(t, links, 4)
+		assert.Equal(t, Record{"post_id": posts[0]["id"], "tag_id": tags[0]["id"]}, links[0])
+		assert.Equal(t, Record{"post_id": posts[0]["id"], "tag_id": tags[1]["id"]}, links[1])
+		assert.Equal(t, Record{"post_id": posts[1]["id"], "tag_id": tags[0]["id"]}, links[2])
+		assert.Equal(t, Record{"post_id": posts[1]["id"], "tag_id": tags[1]["id"]}, links[3])
+	})
+
+	t.Run("linking through named foreign keys", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		users := seeder.InsertMany(t, tx, "user", []Record{
+			{"username": "gopher"},
+			{"username": "rustacean"},
+		})
+		gopher, rustacean := users[0:1], users[1:2]
+
+		links := seeder.Link(t, tx, Join{Table: "follower", To: "follower_id"}, gopher, rustacean)
+
+		assert.Equal(t, []Record{
+			{"follower_id": rustacean[0]["id"], "following_id": gopher[0]["id"]},
+		}, links)
+	})
+
+	t.Run("enforcing unique links", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		gopher := seeder.InsertOne(t, tx, "user", Record{"username": "gopher"})
+		posts := seeder.InsertMany(t, tx, "post", []Record{
+			{"author_id": gopher["id"], "title": "first"},
+		})
+		tags := seeder.InsertMany(t, tx, "tag", []Record{
+			{"name": "go"},
+		})
+
+		seeder.Link(t, tx, Join{Table: "post_tag"}, posts, tags)
+
+		recorded := dumbotest.Record(t, func(t testing.TB) {
+			seeder.Link(t, tx, Join{Table: "post_tag"}, posts, tags)
+		})
+
+		assert.True(t, recorded.Failed())
+		assert.Contains(t, recorded.Errors[0], fmt.Sprintf(
+			`duplicate link between "post" [%v] and "tag" [%v] in join table "post_tag"`,
+			posts[0]["id"], tags[0]["id"],
+		))
+	})
+}
+
+func TestRejectingDuplicateLinks(t *testing.T) {
+	seeder := New()
+	seeder.catalog.tables["post_tag"] = &table{
+		name:       "post_tag",
+		columns:    []column{{name: "post_id", notNull: true}, {name: "tag_id", notNull: true}},
+		primaryKey: []string{"post_id", "tag_id"},
+		foreignKeys: []foreignKey{
+			{name: "post_tag_post_id_fkey", columns: []string{"post_id"}, references: "post", keys: []string{"id"}},
+			{name: "post_tag_tag_id_fkey", columns: []string{"tag_id"}, references: "tag", keys: []string{"id"}},
+		},
+	}
+
+	posts := []Record{{"id": 1}}
+	tags := []Record{{"id": 1}, {"id": 1}}
+
+	recorded := dumbotest.Record(t, func(t testing.TB) {
+		seeder.Link(t, nil, Join{Table: "post_tag"}, posts, tags)
+	})
+
+	assert.True(t, recorded.Failed())
+	assert.Contains(t, recorded.Errors[0], `duplicate link between "post" [1] and "tag" [1] in join table "post_tag"`)
+	assert.Empty(t, seeder.factories)
+}
@@ -0,0 +1,7 @@
+create table "post" (
+  id        serial,
+  author_id int    not null,
+  title     text   not null,
+  primary key (id),
+  foreign key (author_id) references "user" (id)
+);
@@ -0,0 +1,9 @@
+create table "comment" (
+  id        serial,
+  post_id   int    not null,
+  author_id int    not null,
+  body      text   not null,
+  primary key (id),
+  foreign key (post_id) references "post" (id),
+  foreign key (author_id) references "user" (id)
+);
@@ -0,0 +1,7 @@
+create table "follower" (
+  follower_id  int not null,
+  following_id int not null,
+  primary key (follower_id, following_id),
+  foreign key (follower_id) references "user" (id),
+  foreign key (following_id) references "user" (id)
+);
@@ -0,0 +1,6 @@
+create table "tag" (
+  id   serial,
+  name text   not null,
+  primary key (id),
+  unique (name)
+);
@@ -0,0 +1,7 @@
+create table "post_tag" (
+  post_id int not null,
+  tag_id  int not null,
+  primary key (post_id, tag_id),
+  foreign key (post_id) references "post" (id),
+  foreign key (tag_id) references "tag" (id)
+);
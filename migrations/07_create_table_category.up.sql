@@ -0,0 +1,7 @@
+create table "category" (
+  id        serial,
+  parent_id int,
+  name      text   not null,
+  primary key (id),
+  foreign key (parent_id) references "category" (id)
+);
@@ -0,0 +1 @@
+drop table "team", "player" cascade;
@@ -0,0 +1,15 @@
+create table "team" (
+  id         serial,
+  captain_id int    not null,
+  name       text   not null,
+  primary key (id)
+);
+create table "player" (
+  id       serial,
+  team_id  int    not null,
+  username text   not null,
+  primary key (id),
+  foreign key (team_id) references "team" (id) deferrable
+);
+alter table "team"
+  add foreign key (captain_id) references "player" (id) deferrable;
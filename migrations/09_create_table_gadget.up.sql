@@ -0,0 +1,9 @@
+create table "gadget" (
+  id       serial,
+  price    numeric(10, 2),
+  specs    jsonb,
+  sku      uuid,
+  tags     text[],
+  ratings  int[],
+  primary key (id)
+);
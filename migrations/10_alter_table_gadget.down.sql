@@ -0,0 +1,7 @@
+alter table "gadget"
+  drop column mood,
+  drop column size,
+  drop column warranty,
+  drop column manuals;
+drop type "dimensions";
+drop type "mood";
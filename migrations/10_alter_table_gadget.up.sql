@@ -0,0 +1,10 @@
+create type "mood" as enum ('happy', 'sad');
+create type "dimensions" as (
+  width  int,
+  height int
+);
+alter table "gadget"
+  add column mood      mood,
+  add column size      dimensions,
+  add column warranty  int4range,
+  add column manuals   jsonb[];
@@ -0,0 +1,7 @@
+create table "ticket" (
+  id       int generated always as identity,
+  price    int not null,
+  quantity int not null,
+  total    int generated always as (price * quantity) stored,
+  primary key (id)
+);
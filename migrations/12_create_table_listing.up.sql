@@ -0,0 +1,9 @@
+create table "listing" (
+  id        serial,
+  title     varchar(12)  not null,
+  quantity  int          not null check (quantity between 1 and 10),
+  rating    numeric(3,1) check (rating >= 0 and rating <= 5),
+  mood      mood         not null,
+  listed_at timestamptz  not null,
+  primary key (id)
+);
@@ -0,0 +1,86 @@
+package dumbo
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Change the rows of the table that match, returning them. The match must
+// not be empty, see UpdateAll.
+func (d Dumbo) UpdateWhere(t testing.TB, db DB, table string, match Record, set Record) []Record {
+	t.Helper()
+	if len(match) == 0 {
+		require.FailNow(t, fmt.Sprintf("updating table %q requires a match, use UpdateAll to change every row", table))
+	}
+	return d.update(t, db, table, match, set)
+}
+
+// Change every row of the table, returning them.
+func (d Dumbo) UpdateAll(t testing.TB, db DB, table string, set Record) []Record {
+	t.Helper()
+	return d.update(t, db, table, Record{}, set)
+}
+
+func (d Dumbo) update(t testing.TB, db DB, table string, match Record, set Record) []Record {
+	t.Helper()
+	if len(set) == 0 {
+		require.FailNow(t, fmt.Sprintf("updating table %q requires columns to set", table))
+	}
+
+	described, err := d.catalog.table(db, table)
+	require.NoError(t, err, fmt.Sprintf("describing table %q", table))
+
+	columns := sortedColumns(set)
+	sets := make([]string, len(columns))
+	values := make([]any, len(columns))
+	for i, column := range columns {
+		values[i] = set[column]
+		if c, ok := described.column(column); ok {
+			values[i], err = encode(c, set[column])
+			require.NoError(t, err, fmt.Sprintf("encoding column %q", column))
+		}
+		sets[i] = fmt.Sprintf("%q = $%v", column, i+1)
+	}
+
+	conditions, values := d.where(t, db, table, match, values)
+
+	return d.FetchMany(t, db, fmt.Sprintf(`
+		update %q
+		   set %v%v
+		returning *
+	`, table, strings.Join(sets, ", "), conditions), values...)
+}
+
+// Remove the rows of the table that match, returning how many were removed.
+// The match must not be empty, see DeleteAll.
+func (d Dumbo) DeleteWhere(t testing.TB, db DB, table string, match Record) int {
+	t.Helper()
+	if len(match) == 0 {
+		require.FailNow(t, fmt.Sprintf("deleting from table %q requires a match, use DeleteAll to remove every row", table))
+	}
+	return d.delete(t, db, table, match)
+}
+
+// Remove every row of the table, returning how many were removed.
+func (d Dumbo) DeleteAll(t testing.TB, db DB, table string) int {
+	t.Helper()
+	return d.delete(t, db, table, Record{})
+}
+
+func (d Dumbo) delete(t testing.TB, db DB, table string, match Record) int {
+	t.Helper()
+
+	conditions, values := d.where(t, db, table, match, nil)
+
+	deleted := d.FetchMany(t, db, fmt.Sprintf(`
+		delete from %q%v
+		returning *
+	`, table, conditions), values...)
+
+	d.untrack(t, db, table, deleted)
+
+	return len(deleted)
+}
@@ -0,0 +1,160 @@
+package dumbo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/thebearingedge/dumbo/internal/dumbotest"
+)
+
+func TestUpdatingRecords(t *testing.T) {
+	db := dumbotest.RequireDB(t)
+
+	seeder := New()
+
+	t.Run("updating matching rows", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		users := seeder.SeedMany(t, tx, "user", []Record{
+			{"username": "gopher"},
+			{"username": "rustacean"},
+		})
+
+		updated := seeder.UpdateWhere(t, tx, "user",
+			Record{"username": "gopher"},
+			Record{"username": "pythonista"},
+		)
+
+		assert.Equal(t, []Record{{"id": users[0]["id"], "username": "pythonista"}}, updated)
+
+		rustacean := seeder.FetchOne(t, tx, `select * from "user" where "id" = $1`, users[1]["id"])
+		assert.Equal(t, "rustacean", rustacean["username"])
+	})
+
+	t.Run("matching null columns", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		root := seeder.InsertOne(t, tx, "category", Record{"name": "root"})
+		seeder.InsertOne(t, tx, "category", Record{"name": "leaf", "parent_id": root["id"]})
+
+		updated := seeder.UpdateWhere(t, tx, "category",
+			Record{"parent_id": nil, "name": "root"},
+			Record{"name": "trunk"},
+		)
+
+		assert.Len(t, updated, 1)
+		assert.Equal(t, root["id"], updated[0]["id"])
+		assert.Equal(t, "trunk", updated[0]["name"])
+	})
+
+	t.Run("updating encoded columns", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		gadget := seeder.InsertOne(t, tx, "gadget", Record{"tags": []string{"go"}})
+
+		updated := seeder.UpdateWhere(t, tx, "gadget",
+			Record{"tags": []string{"go"}},
+			Record{"tags": []string{"go", "sql"}, "specs": map[string]any{"color": "red"}},
+		)
+
+		assert.Len(t, updated, 1)
+		assert.Equal(t, gadget["id"], updated[0]["id"])
+		assert.Equal(t, []string{"go", "sql"}, updated[0]["tags"])
+		assert.Equal(t, map[string]any{"color": "red"}, updated[0]["specs"])
+	})
+
+	t.Run("updating every row", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		seeder.InsertMany(t, tx, "category", []Record{{"name": "root"}, {"name": "leaf"}})
+
+		updated := seeder.UpdateAll(t, tx, "category", Record{"name": "branch"})
+
+		assert.Len(t, updated, 2)
+		assert.Equal(t, 2, seeder.Count(t, tx, "category", Record{"name": "branch"}))
+	})
+}
+
+func TestDeletingRecords(t *testing.T) {
+	db := dumbotest.RequireDB(t)
+
+	seeder := New()
+
+	t.Run("deleting matching rows", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		seeder.SeedMany(t, tx, "user", []Record{
+			{"username": "gopher"},
+			{"username": "rustacean"},
+		})
+
+		deleted := seeder.DeleteWhere(t, tx, "user", Record{"username": "gopher"})
+
+		assert.Equal(t, 1, deleted)
+		assert.Len(t, seeder.FetchMany(t, tx, `select * from "user"`), 1)
+	})
+
+	t.Run("deleting every row", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		seeder.SeedMany(t, tx, "user", []Record{
+			{"username": "gopher"},
+			{"username": "rustacean"},
+		})
+
+		assert.Equal(t, 2, seeder.DeleteAll(t, tx, "user"))
+		assert.Equal(t, 0, seeder.Count(t, tx, "user", Record{}))
+	})
+
+	t.Run("forgetting deleted parents", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		seeder := New(Factory{
+			Table:        "post",
+			NewRecord:    func(Draw) Record { return Record{"title": "first"} },
+			Associations: []Association{{Column: "author_id", Strategy: ReuseRandom()}},
+		})
+
+		users := seeder.InsertMany(t, tx, "user", []Record{
+			{"username": "gopher"},
+			{"username": "rustacean"},
+		})
+		seeder.DeleteWhere(t, tx, "user", Record{"username": "gopher"})
+
+		post := seeder.InsertOne(t, tx, "post", Record{})
+
+		assert.Equal(t, users[1]["id"], post["author_id"])
+	})
+}
+
+func TestRequiringMutationBounds(t *testing.T) {
+	seeder := New()
+
+	t.Run("requiring columns to set", func(t *testing.T) {
+		recorded := dumbotest.Record(t, func(t testing.TB) {
+			seeder.UpdateWhere(t, nil, "user", Record{"username": "gopher"}, Record{})
+		})
+
+		assert.True(t, recorded.Failed())
+		assert.Contains(t, recorded.Errors[0], `updating table "user" requires columns to set`)
+	})
+
+	t.Run("requiring a match to update", func(t *testing.T) {
+		recorded := dumbotest.Record(t, func(t testing.TB) {
+			seeder.UpdateWhere(t, nil, "user", Record{}, Record{"username": "gopher"})
+		})
+
+		assert.True(t, recorded.Failed())
+		assert.Contains(t, recorded.Errors[0], `updating table "user" requires a match, use UpdateAll to change every row`)
+	})
+
+	t.Run("requiring a match to delete", func(t *testing.T) {
+		recorded := dumbotest.Record(t, func(t testing.TB) {
+			seeder.DeleteWhere(t, nil, "user", Record{})
+		})
+
+		assert.True(t, recorded.Failed())
+		assert.Contains(t, recorded.Errors[0], `deleting from table "user" requires a match, use DeleteAll to remove every row`)
+	})
+}
@@ -0,0 +1,344 @@
+package dumbo
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/require"
+)
+
+// A Dataset is the records of each table in a run of a property.
+type Dataset map[string][]Record
+
+// A Sample generates up to Max rows for the table with its factory, or empty
+// rows for tables without one.
+type Sample struct {
+	Table string
+	Max   int
+}
+
+// A Property is an invariant that should hold for any dataset generated from
+// the samples, checked by failing t like a test.
+type Property struct {
+	Samples []Sample
+	// Runs is the number of datasets to check, 100 when zero.
+	Runs  int
+	Check func(t testing.TB, db DB, inserted Dataset)
+}
+
+// The most datasets tried while shrinking a failing one.
+const maxShrinks = 1000
+
+// Check the property against datasets generated from the test's random
+// source, inserting each in a savepoint of db, which must be a transaction.
+// When a dataset fails the check it is shrunk to fewer rows and simpler
+// values while it keeps failing, and the smallest is reported with the
+// failures it causes. Rerun with the logged seed to replay it. The test also
+// fails when fewer than half of the datasets could be inserted to check.
+func (d *Dumbo) ForAll(t testing.TB, db DB, p Property) {
+	t.Helper()
+
+	runs := p.Runs
+	if runs == 0 {
+		runs = 100
+	}
+
+	r := d.Rand(t)
+	attempts := 0
+	checked := 0
+	var invalid []string
+
+	for i := 0; i < runs; i++ {
+		dataset := make(Dataset, len(p.Samples))
+		scope := []*run{newRun()}
+		for _, sample := range p.Samples {
+			n := r.Intn(sample.Max + 1)
+			factory, hasFactory := d.factories[sample.Table]
+			if !hasFactory {
+				dataset[sample.Table] = make([]Record, n)
+				for j := range dataset[sample.Table] {
+					dataset[sample.Table][j] = Record{}
+				}
+				continue
+			}
+			described, err := d.catalog.table(db, sample.Table)
+			require.NoError(t, err, fmt.Sprintf("describing table %q", sample.Table))
+
+			scope[0].indexes[sample.Table] = make([]Index, len(factory.UniqueBy))
+			for j := range factory.UniqueBy {
+				scope[0].indexes[sample.Table][j] = make(Index)
+			}
+			records, _, err := generate(scope, d.config.retries, r, d.Now(t), factory, described, make([]Record, n), false)
+			require.NoError(t, err, fmt.Sprintf("generating records for table %q", sample.Table))
+			dataset[sample.Table] = records
+		}
+
+		attempts++
+		failures, valid := d.attempt(t, db, p, dataset, i+1)
+		if !valid {
+			if invalid == nil {
+				invalid = failures
+			}
+			continue
+		}
+		checked++
+		if len(failures) == 0 {
+			continue
+		}
+
+		shrinks := 0
+		for shrunk := true; shrunk && attempts < maxShrinks; {
+			shrunk = false
+			for _, candidate := range shrink(p.Samples, dataset) {
+				if attempts >= maxShrinks {
+					break
+				}
+				attempts++
+				if f, valid := d.attempt(t, db, p, candidate, i+1); valid && len(f) > 0 {
+					dataset, failures, shrunk = candidate, f, true
+					shrinks++
+					break
+				}
+			}
+		}
+
+		t.Errorf(
+			"property failed on dataset %v of %v, shrunk %v time(s) to:\n\n%v\nfailures:\n\n%v",
+			i+1, runs, shrinks, d.describeDataset(db, p.Samples, dataset), strings.Join(failures, "\n"),
+		)
+		return
+	}
+
+	if checked*2 < runs {
+		t.Errorf(
+			"property checked only %v of %v datasets, the others failed to insert, first with:\n\n%v",
+			checked, runs, strings.Join(invalid, "\n"),
+		)
+	}
+}
+
+// A probe is the TB of one attempt of a property. It records failures
+// instead of failing the test, so that failing datasets can be shrunk, and
+// drops logs, which are not failures.
+type probe struct {
+	iteration
+	checking bool
+	failed   bool
+	failures []string
+}
+
+func (p *probe) Fail() {
+	p.failed = true
+}
+
+func (p *probe) Failed() bool {
+	return p.failed
+}
+
+func (p *probe) FailNow() {
+	p.Fail()
+	runtime.Goexit()
+}
+
+func (p *probe) Log(args ...any) {}
+
+func (p *probe) Logf(format string, args ...any) {}
+
+func (p *probe) Error(args ...any) {
+	p.failures = append(p.failures, fmt.Sprintln(args...))
+	p.Fail()
+}
+
+func (p *probe) Errorf(format string, args ...any) {
+	p.failures = append(p.failures, fmt.Sprintf(format, args...))
+	p.Fail()
+}
+
+func (p *probe) Fatal(args ...any) {
+	p.Error(args...)
+	p.FailNow()
+}
+
+func (p *probe) Fatalf(format string, args ...any) {
+	p.Errorf(format, args...)
+	p.FailNow()
+}
+
+// Insert the dataset in a savepoint and check the property against it. The
+// failures are those of the check, and the attempt is invalid when inserting
+// the dataset fails instead, with the failures of the insert. Attempts of the
+// nth dataset and its shrunk versions share a random source derived from n,
+// so that they make the same choices.
+func (d *Dumbo) attempt(t testing.TB, db DB, p Property, dataset Dataset, n int) ([]string, bool) {
+	pr := &probe{iteration: iteration{TB: t, name: fmt.Sprintf("%v#%v", t.Name(), n)}}
+
+	_, err := d.exec(t, db, `savepoint "dumbo_property"`)
+	require.NoError(t, err, "creating savepoint for property")
+	d.runs = append(d.runs, newRun())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				pr.Errorf("panic: %v", recovered)
+			}
+		}()
+
+		inserted := make(Dataset, len(p.Samples))
+		for _, sample := range p.Samples {
+			if records := dataset[sample.Table]; len(records) > 0 {
+				inserted[sample.Table] = d.InsertMany(pr, db, sample.Table, records)
+			}
+		}
+
+		pr.checking = true
+		p.Check(pr, db, inserted)
+	}()
+	<-done
+
+	// cleanups may fail too
+	failures := pr.failures
+	pr.done()
+	d.runs = d.runs[:len(d.runs)-1]
+
+	_, err = d.exec(t, db, `rollback to savepoint "dumbo_property"`)
+	require.NoError(t, err, "rolling back savepoint for property")
+	_, err = d.exec(t, db, `release savepoint "dumbo_property"`)
+	require.NoError(t, err, "releasing savepoint for property")
+
+	if !pr.checking {
+		return failures, false
+	}
+	if !pr.failed {
+		return nil, true
+	}
+	return failures, true
+}
+
+// The datasets one step smaller than the dataset: first without each row,
+// then with each value simpler.
+func shrink(samples []Sample, dataset Dataset) []Dataset {
+	candidates := make([]Dataset, 0)
+
+	with := func(table string, records []Record) Dataset {
+		candidate := make(Dataset, len(dataset))
+		for t, r := range dataset {
+			candidate[t] = r
+		}
+		candidate[table] = records
+		return candidate
+	}
+
+	for i := len(samples) - 1; i >= 0; i-- {
+		table := samples[i].Table
+		records := dataset[table]
+		for j := range records {
+			without := make([]Record, 0, len(records)-1)
+			without = append(without, records[:j]...)
+			without = append(without, records[j+1:]...)
+			candidates = append(candidates, with(table, without))
+		}
+	}
+
+	for _, sample := range samples {
+		records := dataset[sample.Table]
+		for j, record := range records {
+			for _, column := range sortedColumns(record) {
+				for _, value := range simpler(record[column]) {
+					changed := make([]Record, len(records))
+					copy(changed, records)
+					changed[j] = make(Record, len(record))
+					for c, v := range record {
+						changed[j][c] = v
+					}
+					changed[j][column] = value
+					candidates = append(candidates, with(sample.Table, changed))
+				}
+			}
+		}
+	}
+
+	return candidates
+}
+
+// Simpler values of the same type: empty and shorter strings, zero and
+// smaller numbers, and false.
+func simpler(value any) []any {
+	if value == nil {
+		return nil
+	}
+	v := reflect.ValueOf(value)
+	convert := func(x any) any {
+		return reflect.ValueOf(x).Convert(v.Type()).Interface()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		s := v.String()
+		switch {
+		case s == "":
+			return nil
+		case utf8.RuneCountInString(s) == 1:
+			return []any{convert("")}
+		default:
+			runes := []rune(s)
+			return []any{convert(""), convert(string(runes[:len(runes)/2]))}
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch n := v.Int(); {
+		case n == 0:
+			return nil
+		case n/2 == 0:
+			return []any{convert(int64(0))}
+		default:
+			return []any{convert(int64(0)), convert(n / 2)}
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch n := v.Uint(); {
+		case n == 0:
+			return nil
+		case n/2 == 0:
+			return []any{convert(uint64(0))}
+		default:
+			return []any{convert(uint64(0)), convert(n / 2)}
+		}
+	case reflect.Float32, reflect.Float64:
+		switch f := v.Float(); {
+		case f == 0:
+			return nil
+		case f == float64(int64(f)):
+			return []any{convert(0.0)}
+		default:
+			return []any{convert(0.0), convert(float64(int64(f)))}
+		}
+	case reflect.Bool:
+		if v.Bool() {
+			return []any{convert(false)}
+		}
+	}
+	return nil
+}
+
+func (d *Dumbo) describeDataset(db DB, samples []Sample, dataset Dataset) string {
+	var b strings.Builder
+	for _, sample := range samples {
+		fmt.Fprintf(&b, "%v:\n", sample.Table)
+		described, err := d.catalog.table(db, sample.Table)
+		for i, record := range dataset[sample.Table] {
+			columns := sortedColumns(record)
+			if err == nil {
+				columns = described.order(columns)
+			}
+			values := make([]string, 0, len(record))
+			for _, column := range columns {
+				values = append(values, fmt.Sprintf("%v=%v", column, show(record[column])))
+			}
+			fmt.Fprintf(&b, "  %v: %v\n", i+1, strings.Join(values, ", "))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,107 @@
+package dumbo
+
+import (
+	"testing"
+
+	"github.com/go-faker/faker/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/thebearingedge/dumbo/internal/dumbotest"
+)
+
+func TestCheckingProperties(t *testing.T) {
+	db := dumbotest.RequireDB(t)
+
+	seeder := New(Factory{
+		Table: "user",
+		NewRecord: func(Draw) Record {
+			return Record{"username": faker.Username()}
+		},
+		UniqueBy: []Indexer{
+			func(r Record) string { return r["username"].(string) },
+		},
+	})
+
+	users := []Sample{{Table: "user", Max: 10}}
+
+	t.Run("passing a property that holds", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		seeder.ForAll(t, tx, Property{
+			Samples: users,
+			Runs:    20,
+			Check: func(t testing.TB, db DB, inserted Dataset) {
+				assert.Equal(t, len(inserted["user"]), seeder.Count(t, db, "user", Record{}))
+			},
+		})
+	})
+
+	t.Run("shrinking a dataset that breaks a property", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+		outer := &probe{iteration: iteration{TB: t, name: t.Name()}}
+
+		seeder.ForAll(outer, tx, Property{
+			Samples: users,
+			Check: func(t testing.TB, db DB, inserted Dataset) {
+				assert.Less(t, seeder.Count(t, db, "user", Record{}), 3)
+			},
+		})
+		outer.done()
+
+		assert.True(t, outer.failed)
+		assert.Len(t, outer.failures, 1)
+		assert.Contains(t, outer.failures[0], "user:\n  1: username=\"\"\n  2: ")
+		assert.NotContains(t, outer.failures[0], "  4: ")
+	})
+
+	t.Run("failing when datasets cannot be inserted", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		clashing := New(Factory{
+			Table: "user",
+			NewRecord: func(Draw) Record {
+				return Record{"username": "gopher"}
+			},
+		})
+
+		recorded := dumbotest.Record(t, func(t testing.TB) {
+			clashing.ForAll(t, tx, Property{
+				Samples: users,
+				Runs:    20,
+				Check:   func(t testing.TB, db DB, inserted Dataset) {},
+			})
+		})
+
+		assert.True(t, recorded.Failed())
+		assert.Contains(t, recorded.Errors[0], "property checked only")
+		assert.Contains(t, recorded.Errors[0], "of 20 datasets, the others failed to insert")
+	})
+}
+
+func TestShrinkingDatasets(t *testing.T) {
+	samples := []Sample{{Table: "user"}, {Table: "post"}}
+	dataset := Dataset{
+		"user": {{"username": "go"}},
+		"post": {{"title": "a", "draft": true}, {"title": "b"}},
+	}
+
+	candidates := shrink(samples, dataset)
+
+	assert.Equal(t, []Dataset{
+		{"user": dataset["user"], "post": {{"title": "b"}}},
+		{"user": dataset["user"], "post": {{"title": "a", "draft": true}}},
+		{"user": {}, "post": dataset["post"]},
+		{"user": {{"username": ""}}, "post": dataset["post"]},
+		{"user": {{"username": "g"}}, "post": dataset["post"]},
+		{"user": dataset["user"], "post": {{"title": "a", "draft": false}, {"title": "b"}}},
+		{"user": dataset["user"], "post": {{"title": "", "draft": true}, {"title": "b"}}},
+		{"user": dataset["user"], "post": {{"title": "a", "draft": true}, {"title": ""}}},
+	}, candidates)
+
+	assert.Equal(t, []any{"", "¡o"}, simpler("¡olé"))
+	assert.Equal(t, []any{""}, simpler("é"))
+	assert.Equal(t, []any{int64(0), int64(21)}, simpler(int64(42)))
+	assert.Equal(t, []any{0.0, 1.0}, simpler(1.5))
+	assert.Nil(t, simpler(0))
+	assert.Nil(t, simpler(nil))
+}
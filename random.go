@@ -0,0 +1,161 @@
+package dumbo
+
+import (
+	"flag"
+	"hash/fnv"
+	"math/rand"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-faker/faker/v4"
+)
+
+var seedFlag = flag.Int64("dumbo.seed", 0, "seed for the random values generated by dumbo")
+
+type randomness struct {
+	once    sync.Once
+	seed    int64
+	mu      sync.Mutex
+	sources map[string]*rand.Rand
+}
+
+func newRandomness() *randomness {
+	return &randomness{sources: make(map[string]*rand.Rand)}
+}
+
+// The seed of every random value generated, taken from the config, the
+// -dumbo.seed test flag or the DUMBO_SEED environment variable, in that
+// order, or else from the clock.
+func (d *Dumbo) Seed() int64 {
+	d.random.once.Do(func() {
+		d.random.seed = d.config.Seed
+		if d.random.seed == 0 {
+			d.random.seed = *seedFlag
+		}
+		if d.random.seed == 0 {
+			d.random.seed, _ = strconv.ParseInt(os.Getenv("DUMBO_SEED"), 10, 64)
+		}
+		if d.random.seed == 0 {
+			d.random.seed = time.Now().UnixNano()
+		}
+	})
+	return d.random.seed
+}
+
+// The random source of the test. It is derived from the seed and the name of
+// the test, so a test regenerates the same values whether it runs alone or
+// with others. The seed is logged when the test fails.
+//
+// Faker draws from the same source until the test is done. Faker only has one
+// source for the whole process though, so its values are not reproducible in
+// tests that run in parallel, unlike the values drawn from the source itself.
+func (d *Dumbo) Rand(t testing.TB) *rand.Rand {
+	seed := d.Seed()
+	name := t.Name()
+
+	d.random.mu.Lock()
+	defer d.random.mu.Unlock()
+
+	if r, ok := d.random.sources[name]; ok {
+		return r
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	t.Cleanup(func() {
+		if t.Failed() {
+			t.Logf("dumbo seed %v, rerun with -dumbo.seed=%v or DUMBO_SEED=%v", seed, seed, seed)
+		}
+	})
+
+	return d.random.install(t, rand.NewSource(seed^int64(h.Sum64())))
+}
+
+// Make src the random source of the test until it is done, locked so that
+// faker can draw from it alongside the test. The caller holds m.mu.
+func (m *randomness) install(t testing.TB, src rand.Source) *rand.Rand {
+	name := t.Name()
+	locked := &lockedSource{src: src}
+	r := rand.New(locked)
+	m.sources[name] = r
+
+	fakerSource.route(t, locked)
+
+	t.Cleanup(func() {
+		m.mu.Lock()
+		delete(m.sources, name)
+		m.mu.Unlock()
+	})
+
+	return r
+}
+
+// A lockedSource is safe to draw from concurrently.
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Int63()
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if src, ok := s.src.(rand.Source64); ok {
+		return src.Uint64()
+	}
+	return uint64(s.src.Int63())>>31 | uint64(s.src.Int63())<<32
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.src.Seed(seed)
+}
+
+// The source faker draws from, installed once for the process and routed to
+// the random source of the test that installed one last.
+var fakerSource = &routedSource{src: &lockedSource{src: rand.NewSource(time.Now().UnixNano())}}
+
+// A routedSource draws from a source that is swapped as tests come and go.
+type routedSource struct {
+	once sync.Once
+	mu   sync.Mutex
+	src  rand.Source
+}
+
+// Route the source to src until the test is done, then back to the source it
+// drew from before.
+func (s *routedSource) route(t testing.TB, src rand.Source) {
+	s.once.Do(func() { faker.SetRandomSource(s) })
+
+	s.mu.Lock()
+	previous := s.src
+	s.src = src
+	s.mu.Unlock()
+
+	t.Cleanup(func() {
+		s.mu.Lock()
+		s.src = previous
+		s.mu.Unlock()
+	})
+}
+
+func (s *routedSource) current() rand.Source {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src
+}
+
+func (s *routedSource) Int63() int64 {
+	return s.current().Int63()
+}
+
+func (s *routedSource) Seed(int64) {}
@@ -0,0 +1,84 @@
+package dumbo
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-faker/faker/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/thebearingedge/dumbo/internal/dumbotest"
+)
+
+func TestReproducibleRandomness(t *testing.T) {
+	t.Run("deriving the same source from the same seed", func(t *testing.T) {
+		config := Defaults()
+		config.Seed = 42
+
+		first := NewWithConfig(config)
+		second := NewWithConfig(config)
+
+		assert.Equal(t, int64(42), first.Seed())
+		assert.Equal(t, first.Rand(t).Int63(), second.Rand(t).Int63())
+	})
+
+	t.Run("deriving a source per test", func(t *testing.T) {
+		config := Defaults()
+		config.Seed = 42
+
+		seeder := NewWithConfig(config)
+
+		var values []int64
+		for _, name := range []string{"one", "two"} {
+			t.Run(name, func(t *testing.T) {
+				values = append(values, seeder.Rand(t).Int63())
+			})
+		}
+
+		assert.NotEqual(t, values[0], values[1])
+	})
+
+	t.Run("drawing faker values from the source of the test", func(t *testing.T) {
+		config := Defaults()
+		config.Seed = 42
+
+		username := func() (username string) {
+			dumbotest.Record(t, func(t testing.TB) {
+				seeder := NewWithConfig(config)
+				seeder.Rand(t)
+				username = faker.Username()
+			})
+			return username
+		}
+
+		assert.Equal(t, username(), username())
+	})
+
+	t.Run("generating the same records from the same seed", func(t *testing.T) {
+		db := dumbotest.RequireDB(t)
+
+		tx := dumbotest.RequireBegin(t, db)
+
+		usernames := func(seed int64) []any {
+			config := Defaults()
+			config.Seed = seed
+
+			seeder := NewWithConfig(config, Factory{
+				Table: "user",
+				NewRecord: func(d Draw) Record {
+					return Record{
+						"username": fmt.Sprintf("%v%v", faker.Username(), d.Rand.Intn(100)),
+					}
+				},
+			})
+
+			var usernames []any
+			for _, user := range seeder.SeedMany(t, tx, "user", []Record{{}, {}, {}}) {
+				usernames = append(usernames, user["username"])
+			}
+			return usernames
+		}
+
+		assert.Equal(t, usernames(7), usernames(7))
+	})
+}
@@ -0,0 +1,97 @@
+package dumbo
+
+import (
+	"database/sql"
+	"fmt"
+	"math/rand"
+)
+
+// A Reference is replaced by a column value of another row when inserted.
+type Reference struct {
+	record Record
+	table  string
+	column string
+}
+
+// Refer to the column of a record that was already inserted.
+func Ref(record Record, column string) Reference {
+	return Reference{record: record, column: column}
+}
+
+// Refer to the column of any row already in the table, picked at insert time.
+func Existing(table string, column string) Reference {
+	return Reference{table: table, column: column}
+}
+
+// A scanner scans the first row returned by a query into dest.
+type scanner func(dest []any, query string, values ...any) error
+
+func (r Reference) resolve(scan scanner, random *rand.Rand) (any, error) {
+	if r.table == "" {
+		value, ok := r.record[r.column]
+		if !ok {
+			return nil, fmt.Errorf(
+				"referenced record has no column %q, was it returned by an insert?",
+				r.column,
+			)
+		}
+		return value, nil
+	}
+
+	var count int
+	err := scan([]any{&count}, fmt.Sprintf(`select count(*) from %q`, r.table))
+	if err != nil {
+		return nil, fmt.Errorf("picking existing row from table %q: %w", r.table, err)
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("no existing rows in table %q to reference", r.table)
+	}
+
+	var value any
+	err = scan([]any{&value}, fmt.Sprintf(
+		`select %q from %q order by %q limit 1 offset $1`,
+		r.column, r.table, r.column,
+	), random.Intn(count))
+	if err != nil {
+		return nil, fmt.Errorf("picking existing row from table %q: %w", r.table, err)
+	}
+
+	return value, nil
+}
+
+// Scan the first row returned by the query.
+func scanOne(db DB, dest []any, query string, values ...any) error {
+	rows, err := db.Query(query, values...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+
+	return rows.Scan(dest...)
+}
+
+// Copy the records, replacing references with the values they point to.
+func resolve(scan scanner, random *rand.Rand, records []Record) ([]Record, error) {
+	resolved := make([]Record, len(records))
+	for i, record := range records {
+		resolved[i] = make(Record, len(record))
+		for column, value := range record {
+			if ref, ok := value.(Reference); ok {
+				v, err := ref.resolve(scan, random)
+				if err != nil {
+					return nil, fmt.Errorf("resolving column %q: %w", column, err)
+				}
+				value = v
+			}
+			resolved[i][column] = value
+		}
+	}
+	return resolved, nil
+}
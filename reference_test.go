@@ -0,0 +1,47 @@
+package dumbo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/thebearingedge/dumbo/internal/dumbotest"
+)
+
+func TestReferencingRecords(t *testing.T) {
+	db := dumbotest.RequireDB(t)
+
+	seeder := New()
+
+	t.Run("referencing an inserted record", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		gopher := seeder.InsertOne(t, tx, "user", Record{"username": "gopher"})
+		post := seeder.InsertOne(t, tx, "post", Record{
+			"author_id": Ref(gopher, "id"),
+			"title":     "first",
+		})
+
+		assert.Equal(t, gopher["id"], post["author_id"])
+	})
+
+	t.Run("referencing an existing row", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		gopher := seeder.SeedOne(t, tx, "user", Record{"username": "gopher"})
+		post := seeder.InsertOne(t, tx, "post", Record{
+			"author_id": Existing("user", "id"),
+			"title":     "first",
+		})
+
+		assert.Equal(t, gopher["id"], post["author_id"])
+	})
+
+	t.Run("referencing a record that was not inserted", func(t *testing.T) {
+		_, err := resolve(nil, nil, []Record{
+			{"author_id": Ref(Record{"username": "gopher"}, "id")},
+		})
+
+		assert.EqualError(t, err, `resolving column "author_id": referenced record has no column "id", was it returned by an insert?`)
+	})
+}
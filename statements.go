@@ -0,0 +1,68 @@
+package dumbo
+
+import (
+	"database/sql"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// A Preparer is a DB that can prepare statements, like *sql.DB and *sql.Tx.
+type Preparer interface {
+	Prepare(string) (*sql.Stmt, error)
+}
+
+type statementKey struct {
+	db    DB
+	query string
+}
+
+type statements struct {
+	mu       sync.Mutex
+	prepared map[statementKey]*sql.Stmt
+}
+
+func newStatements() *statements {
+	return &statements{prepared: make(map[statementKey]*sql.Stmt)}
+}
+
+// Run the query, through a cached prepared statement when configured and the
+// DB can prepare one. The statement is closed when the test that prepared it
+// is done, since it may belong to a transaction that ends with the test.
+func (d *Dumbo) query(t testing.TB, db DB, query string, values ...any) (*sql.Rows, error) {
+	preparer, ok := db.(Preparer)
+	if !d.config.CacheStatements || !ok || !reflect.TypeOf(db).Comparable() {
+		return db.Query(query, values...)
+	}
+
+	key := statementKey{db: db, query: query}
+
+	d.statements.mu.Lock()
+	stmt, cached := d.statements.prepared[key]
+	d.statements.mu.Unlock()
+
+	if !cached {
+		prepared, err := preparer.Prepare(query)
+		if err != nil {
+			return nil, err
+		}
+		stmt = prepared
+
+		d.statements.mu.Lock()
+		d.statements.prepared[key] = stmt
+		d.statements.mu.Unlock()
+
+		// statements outlive the iterations of benchmarks and properties
+		if it, ok := t.(interface{ outer() testing.TB }); ok {
+			t = it.outer()
+		}
+		t.Cleanup(func() {
+			d.statements.mu.Lock()
+			delete(d.statements.prepared, key)
+			d.statements.mu.Unlock()
+			_ = stmt.Close()
+		})
+	}
+
+	return stmt.Query(values...)
+}
@@ -0,0 +1,38 @@
+package dumbo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/thebearingedge/dumbo/internal/dumbotest"
+)
+
+func TestCachingStatements(t *testing.T) {
+	db := dumbotest.RequireDB(t)
+
+	config := Defaults()
+	config.CacheStatements = true
+
+	seeder := NewWithConfig(config)
+
+	t.Run("reusing statements for records of the same shape", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		seeder.InsertOne(t, tx, "user", Record{"username": "gopher"})
+		seeder.InsertOne(t, tx, "user", Record{"username": "rustacean"})
+		assert.Len(t, seeder.statements.prepared, 1)
+
+		seeder.InsertMany(t, tx, "user", []Record{
+			{"username": "pythonista"},
+			{"username": "rubyist"},
+		})
+		assert.Len(t, seeder.statements.prepared, 2)
+
+		assert.Equal(t, 4, seeder.Count(t, tx, "user", Record{"username": In("gopher", "rustacean", "pythonista", "rubyist")}))
+	})
+
+	t.Run("closing statements with the test", func(t *testing.T) {
+		assert.Empty(t, seeder.statements.prepared)
+	})
+}
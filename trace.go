@@ -0,0 +1,109 @@
+package dumbo
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// A Statement is a query or command that dumbo ran against the database.
+type Statement struct {
+	Query string
+	Args  []any
+	Start time.Time
+	// Duration is the time until the rows were fetched or the command done.
+	Duration time.Duration
+	// Rows is the number of rows returned or affected.
+	Rows int64
+	Err  error
+}
+
+// A Tracer receives every statement that dumbo runs for a test, except the
+// catalog lookups that describe tables once per Dumbo. Start and Duration
+// are enough to record a span in a tracing system after the fact.
+type Tracer interface {
+	Trace(t testing.TB, s Statement)
+}
+
+// A TracerFunc is a function used as a Tracer.
+type TracerFunc func(t testing.TB, s Statement)
+
+func (f TracerFunc) Trace(t testing.TB, s Statement) {
+	f(t, s)
+}
+
+// A LogTracer logs statements with t.Logf, so go test prints them when the
+// test fails or runs with -v.
+type LogTracer struct{}
+
+func (LogTracer) Trace(t testing.TB, s Statement) {
+	t.Helper()
+	outcome := fmt.Sprintf("%v row(s)", s.Rows)
+	if s.Err != nil {
+		outcome = fmt.Sprintf("error: %v", s.Err)
+	}
+	t.Logf("dumbo: %v in %v\n%v\nargs: %v", outcome, s.Duration, s.Query, s.Args)
+}
+
+// Start timing the statement. Finish with the rows it returned or affected
+// and its error to pass it to the configured tracer.
+func (d *Dumbo) trace(t testing.TB, query string, values []any) func(rows int64, err error) {
+	start := time.Now()
+	return func(rows int64, err error) {
+		if d.config.Tracer == nil {
+			return
+		}
+		d.config.Tracer.Trace(t, Statement{
+			Query:    query,
+			Args:     values,
+			Start:    start,
+			Duration: time.Since(start),
+			Rows:     rows,
+			Err:      err,
+		})
+	}
+}
+
+// Run the query and fetch every row it returns.
+func (d *Dumbo) fetch(t testing.TB, db DB, query string, values ...any) ([]Record, error) {
+	finish := d.trace(t, query, values)
+	rows, err := db.Query(query, values...)
+	if err != nil {
+		finish(0, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	fetched := fetchAll(t, rows, d.config.Decoders)
+	finish(int64(len(fetched)), nil)
+	return fetched, nil
+}
+
+// Run the command.
+func (d *Dumbo) exec(t testing.TB, db DB, query string, values ...any) (sql.Result, error) {
+	finish := d.trace(t, query, values)
+	result, err := db.Exec(query, values...)
+	if err != nil {
+		finish(0, err)
+		return nil, err
+	}
+	affected, _ := result.RowsAffected()
+	finish(affected, nil)
+	return result, nil
+}
+
+// Scan the first row the query returns.
+func (d *Dumbo) scanOne(t testing.TB, db DB, dest []any, query string, values ...any) error {
+	finish := d.trace(t, query, values)
+	err := scanOne(db, dest, query, values...)
+	switch err {
+	case nil:
+		finish(1, nil)
+	case sql.ErrNoRows:
+		finish(0, nil)
+	default:
+		finish(0, err)
+	}
+	return err
+}
@@ -0,0 +1,61 @@
+package dumbo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/thebearingedge/dumbo/internal/dumbotest"
+)
+
+func TestTracingStatements(t *testing.T) {
+	db := dumbotest.RequireDB(t)
+
+	var traced []Statement
+
+	config := Defaults()
+	config.Tracer = TracerFunc(func(t testing.TB, s Statement) {
+		traced = append(traced, s)
+	})
+
+	seeder := NewWithConfig(config)
+
+	t.Run("tracing inserts, fetches and commands", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+		traced = nil
+
+		seeder.SeedMany(t, tx, "user", []Record{{"username": "gopher"}, {"username": "gogopher"}})
+		seeder.FetchOne(t, tx, `select * from "user" where "username" = $1`, "gopher")
+
+		assert.Len(t, traced, 3)
+		assert.Contains(t, traced[0].Query, "truncate")
+		assert.Contains(t, traced[1].Query, "insert")
+		assert.Equal(t, int64(2), traced[1].Rows)
+		assert.Equal(t, []any{"gopher"}, traced[2].Args)
+		assert.Equal(t, int64(1), traced[2].Rows)
+		for _, s := range traced {
+			assert.NoError(t, s.Err)
+			assert.False(t, s.Start.IsZero())
+		}
+	})
+
+	t.Run("logging statements", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		config := Defaults()
+		config.Tracer = LogTracer{}
+
+		logged := NewWithConfig(config)
+		logged.InsertOne(t, tx, "user", Record{"username": "gopher"})
+
+		recorded := dumbotest.Record(t, func(t testing.TB) {
+			logged.FetchOne(t, tx, `select * from "user" where "username" = $1`, "gopher")
+		})
+
+		assert.False(t, recorded.Failed())
+		assert.Len(t, recorded.Logs, 1)
+		assert.Contains(t, recorded.Logs[0], "dumbo: 1 row(s) in ")
+		assert.Contains(t, recorded.Logs[0], `select * from "user" where "username" = $1`)
+		assert.Contains(t, recorded.Logs[0], "args: [gopher]")
+	})
+}
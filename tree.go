@@ -0,0 +1,91 @@
+package dumbo
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// A Tree describes a hierarchy of rows in a table that references itself.
+//
+// Roots rows are inserted at the top level (one when left at zero) and every
+// row above the last of Depth levels gets Branching children. Via names the
+// self-referencing foreign key column when the table has more than one.
+// Partial, when set, overrides the generated fields of each row by its level
+// and position within the level.
+type Tree struct {
+	Table     string
+	Depth     int
+	Branching int
+	Roots     int
+	Via       string
+	Partial   func(level, index int) Record
+}
+
+// A Branch is an inserted row of a tree.
+type Branch struct {
+	Record   Record
+	Parent   *Branch
+	Children []*Branch
+}
+
+// Add a hierarchy of records level by level, returning the roots.
+func (d *Dumbo) InsertTree(t testing.TB, db DB, tree Tree) []*Branch {
+	t.Helper()
+
+	fk, err := d.childKey(db, tree.Table, tree.Via, tree.Table)
+	require.NoError(t, err, fmt.Sprintf("describing tree table %q", tree.Table))
+
+	roots := tree.Roots
+	if roots == 0 {
+		roots = 1
+	}
+
+	var (
+		parents []*Branch
+		level   []*Branch
+		top     []*Branch
+	)
+
+	for depth := 0; depth < tree.Depth; depth++ {
+		count := roots
+		if depth > 0 {
+			count = len(parents) * tree.Branching
+		}
+		if count == 0 {
+			break
+		}
+
+		partials := make([]Record, count)
+		level = make([]*Branch, count)
+		for i := range partials {
+			partials[i] = Record{}
+			if tree.Partial != nil {
+				for column, value := range tree.Partial(depth, i) {
+					partials[i][column] = value
+				}
+			}
+			branch := &Branch{}
+			if depth > 0 {
+				branch.Parent = parents[i/tree.Branching]
+				for j, column := range fk.columns {
+					partials[i][column] = branch.Parent.Record[fk.keys[j]]
+				}
+				branch.Parent.Children = append(branch.Parent.Children, branch)
+			}
+			level[i] = branch
+		}
+
+		for i, record := range d.InsertMany(t, db, tree.Table, partials) {
+			level[i].Record = record
+		}
+
+		if depth == 0 {
+			top = level
+		}
+		parents = level
+	}
+
+	return top
+}
@@ -0,0 +1,72 @@
+package dumbo
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-faker/faker/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/thebearingedge/dumbo/internal/dumbotest"
+)
+
+func TestInsertingTrees(t *testing.T) {
+	db := dumbotest.RequireDB(t)
+
+	seeder := New(
+		Factory{
+			Table: "category",
+			NewRecord: func(Draw) Record {
+				return Record{
+					"name": faker.Word(),
+				}
+			},
+		},
+	)
+
+	t.Run("inserting levels of branches", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		roots := seeder.InsertTree(t, tx, Tree{
+			Table:     "category",
+			Depth:     3,
+			Branching: 2,
+		})
+
+		assert.Len(t, roots, 1)
+		root := roots[0]
+		assert.Nil(t, root.Record["parent_id"])
+		assert.Len(t, root.Children, 2)
+		for _, child := range root.Children {
+			assert.Same(t, root, child.Parent)
+			assert.Equal(t, root.Record["id"], child.Record["parent_id"])
+			assert.Len(t, child.Children, 2)
+			for _, grandchild := range child.Children {
+				assert.Equal(t, child.Record["id"], grandchild.Record["parent_id"])
+				assert.Empty(t, grandchild.Children)
+			}
+		}
+
+		count := seeder.FetchOne(t, tx, `select count(*) from "category"`)
+		assert.Equal(t, int64(7), count["count"])
+	})
+
+	t.Run("overriding fields by level", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		roots := seeder.InsertTree(t, tx, Tree{
+			Table:     "category",
+			Depth:     2,
+			Branching: 3,
+			Roots:     2,
+			Partial: func(level, index int) Record {
+				return Record{"name": fmt.Sprintf("%v.%v", level, index)}
+			},
+		})
+
+		assert.Len(t, roots, 2)
+		assert.Equal(t, "0.1", roots[1].Record["name"])
+		assert.Equal(t, "1.0", roots[0].Children[0].Record["name"])
+		assert.Equal(t, "1.5", roots[1].Children[2].Record["name"])
+	})
+}
@@ -0,0 +1,123 @@
+package dumbo
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type conflict struct {
+	target  []string
+	update  bool
+	columns []string
+}
+
+// Keep rows that conflict on the target columns, returning them instead of
+// the records that would have replaced them.
+func OnConflictDoNothing(target ...string) Option {
+	return func(o *options) {
+		o.conflict = &conflict{target: target}
+	}
+}
+
+// Update rows that conflict on the target columns with the inserted values of
+// the columns, or of every inserted column when none are named.
+func OnConflictDoUpdate(target []string, columns ...string) Option {
+	return func(o *options) {
+		o.conflict = &conflict{target: target, update: true, columns: columns}
+	}
+}
+
+func (c conflict) clause(inserted []string) string {
+	target := make([]string, len(c.target))
+	for i, column := range c.target {
+		target[i] = fmt.Sprintf("%q", column)
+	}
+
+	if !c.update {
+		return fmt.Sprintf("\n\t\ton conflict (%v) do nothing", strings.Join(target, ", "))
+	}
+
+	columns := c.columns
+	if len(columns) == 0 {
+		for _, column := range inserted {
+			if !slices.Contains(c.target, column) {
+				columns = append(columns, column)
+			}
+		}
+	}
+	if len(columns) == 0 {
+		// nothing else to change, but the conflicting rows must be returned
+		columns = c.target
+	}
+
+	sets := make([]string, len(columns))
+	for i, column := range columns {
+		sets[i] = fmt.Sprintf("%q = excluded.%q", column, column)
+	}
+
+	return fmt.Sprintf(
+		"\n\t\ton conflict (%v) do update set %v",
+		strings.Join(target, ", "), strings.Join(sets, ", "),
+	)
+}
+
+// Line up the returned rows with the records by their conflict target,
+// selecting any rows that were left alone by the insert.
+func (d *Dumbo) reconcile(t testing.TB, db DB, table string, target []string, returning []string, records []Record, returned []Record) []Record {
+	t.Helper()
+
+	key := func(r Record) string {
+		values := make([]string, len(target))
+		for i, column := range target {
+			values[i] = fmt.Sprint(r[column])
+		}
+		return strings.Join(values, "\x00")
+	}
+
+	rows := make(map[string]Record, len(returned))
+	for _, row := range returned {
+		rows[key(row)] = row
+	}
+
+	matches := make([]string, len(target))
+	for i, column := range target {
+		matches[i] = fmt.Sprintf("%q = $%v", column, i+1)
+	}
+	query := fmt.Sprintf(`
+		select %v
+		  from %q
+		 where %v
+	`, selection(returning), table, strings.Join(matches, " and "))
+
+	reconciled := make([]Record, len(records))
+	for i, record := range records {
+		for _, column := range target {
+			_, ok := record[column]
+			require.True(t, ok, fmt.Sprintf(
+				"upserted record for table %q has no conflict target column %q",
+				table, column,
+			))
+		}
+
+		k := key(record)
+		row, ok := rows[k]
+		if !ok {
+			values := make([]any, len(target))
+			for j, column := range target {
+				values[j] = record[column]
+			}
+			found, err := d.fetch(t, db, query, values...)
+			require.NoError(t, err, fmt.Sprintf("selecting existing row(s) from table %q", table))
+			require.Len(t, found, 1, fmt.Sprintf("selecting existing row(s) from table %q", table))
+			row = found[0]
+			rows[k] = row
+		}
+		reconciled[i] = row
+	}
+
+	return reconciled
+}
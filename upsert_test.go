@@ -0,0 +1,77 @@
+package dumbo
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-faker/faker/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/thebearingedge/dumbo/internal/dumbotest"
+)
+
+func TestUpsertingRecords(t *testing.T) {
+	db := dumbotest.RequireDB(t)
+
+	seeder := New(
+		Factory{
+			Table: "tag",
+			NewRecord: func(Draw) Record {
+				return Record{
+					"name": faker.Word(),
+				}
+			},
+			UniqueBy: []Indexer{
+				func(r Record) string {
+					return fmt.Sprint(r["name"])
+				},
+			},
+		},
+	)
+
+	t.Run("returning existing rows on conflict", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		golang := seeder.InsertOne(t, tx, "tag", Record{"name": "go"})
+
+		tags := seeder.InsertMany(t, tx, "tag", []Record{
+			{"name": "sql"},
+			{"name": "go"},
+		}, OnConflictDoNothing("name"))
+
+		assert.Len(t, tags, 2)
+		assert.Equal(t, "sql", tags[0]["name"])
+		assert.Equal(t, golang, tags[1])
+	})
+
+	t.Run("repeating upserts", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		first := seeder.InsertOne(t, tx, "tag", Record{"name": "go"}, OnConflictDoNothing("name"))
+		second := seeder.InsertOne(t, tx, "tag", Record{"name": "go"}, OnConflictDoNothing("name"))
+
+		assert.Equal(t, first, second)
+	})
+
+	t.Run("updating rows on conflict", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		gopher := seeder.InsertOne(t, tx, "user", Record{"username": "gopher"})
+
+		renamed := seeder.InsertOne(t, tx, "user", Record{
+			"id":       gopher["id"],
+			"username": "gopher2",
+		}, OnConflictDoUpdate([]string{"id"}))
+
+		assert.Equal(t, gopher["id"], renamed["id"])
+		assert.Equal(t, "gopher2", renamed["username"])
+	})
+}
+
+func TestRequiringConflictTargets(t *testing.T) {
+	tag := &table{name: "tag", columns: []column{{name: "id"}, {name: "name"}}}
+
+	_, _, _, err := buildInsert(tag, []Record{{"name": "go"}}, newOptions([]Option{OnConflictDoNothing()}))
+
+	assert.EqualError(t, err, `upserting into table "tag" requires conflict target columns`)
+}
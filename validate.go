@@ -0,0 +1,223 @@
+package dumbo
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// Check every factory against the tables it inserts into, reporting unknown
+// columns, NOT NULL columns that neither the factory nor the database fills,
+// values of the wrong type and UniqueBy indexers that do not match a unique
+// constraint. Run it in a single test to lint all factories.
+func (d *Dumbo) Validate(t testing.TB, db DB) {
+	t.Helper()
+
+	tables := make([]string, 0, len(d.factories))
+	for table := range d.factories {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	r := d.Rand(t)
+
+	for _, table := range tables {
+		described, err := d.catalog.table(db, table)
+		if err != nil {
+			t.Errorf("factory %q: %v", table, err)
+			continue
+		}
+		for _, problem := range validate(r, d.Now(t), d.factories[table], described) {
+			t.Errorf("factory %q: %v", table, problem)
+		}
+	}
+}
+
+func validate(r *rand.Rand, now time.Time, factory Factory, described *table) (problems []string) {
+	defer func() {
+		if err := recover(); err != nil {
+			problems = append(problems, fmt.Sprintf("generating a new record panics: %v", err))
+		}
+	}()
+
+	record := newRecord(r, now, factory, described)
+
+	// associations and timestamps are filled on insert
+	filled := make(map[string]bool)
+	for _, association := range factory.Associations {
+		if fk, ok := described.foreignKey(association.Column); ok {
+			for _, column := range fk.columns {
+				filled[column] = true
+			}
+		}
+	}
+	for _, column := range factory.Timestamps {
+		filled[column] = true
+	}
+
+	for _, name := range described.order(sortedColumns(record)) {
+		c, ok := described.column(name)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unknown column %q", name))
+			continue
+		}
+		if problem := mismatch(c, record[name]); problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+
+	for _, c := range described.columns {
+		if !c.notNull || c.hasDefault || c.identity != "" || c.generated || filled[c.name] {
+			continue
+		}
+		if _, isSet := record[c.name]; !isSet {
+			problems = append(problems, fmt.Sprintf("NOT NULL column %q has no default and is not filled", c.name))
+		}
+	}
+
+	for i, uniqueBy := range factory.UniqueBy {
+		if problem := coverage(i, uniqueBy, record, described); problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+
+	return problems
+}
+
+// Describe why the value cannot be inserted into the column, if it cannot.
+func mismatch(c column, value any) string {
+	if value == nil {
+		if c.notNull {
+			return fmt.Sprintf("NOT NULL column %q is filled with nil", c.name)
+		}
+		return ""
+	}
+	if _, ok := value.(driver.Valuer); ok {
+		return ""
+	}
+	if _, err := encode(c, value); err != nil {
+		return fmt.Sprintf("column %q of type %v cannot hold %T: %v", c.name, c.typ, value, err)
+	}
+
+	fits := true
+	switch c.category {
+	case "N":
+		switch v := value.(type) {
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, Decimal:
+		case string:
+			_, err := strconv.ParseFloat(v, 64)
+			fits = err == nil
+		default:
+			fits = false
+		}
+	case "B":
+		_, fits = value.(bool)
+	case "D":
+		switch value.(type) {
+		case time.Time, string:
+		default:
+			fits = false
+		}
+	case "S":
+		switch value.(type) {
+		case string, []byte, fmt.Stringer:
+		default:
+			fits = false
+		}
+	case "E":
+		label := fmt.Sprint(value)
+		for _, l := range c.labels {
+			if l == label {
+				return ""
+			}
+		}
+		return fmt.Sprintf("enum column %q of type %v has no label %q", c.name, c.typ, label)
+	}
+	if !fits {
+		return fmt.Sprintf("column %q of type %v cannot hold %T", c.name, c.typ, value)
+	}
+	return ""
+}
+
+// Describe why the indexer does not match a unique constraint, if it does
+// not. The columns an indexer depends on are found by changing the values of
+// the record one at a time and watching its key.
+func coverage(i int, uniqueBy Indexer, record Record, described *table) string {
+	key, err := index(uniqueBy, record)
+	if err != nil {
+		return fmt.Sprintf("UniqueBy[%v] panics on a new record: %v", i, err)
+	}
+
+	depends := make([]string, 0, 1)
+	for _, name := range described.order(sortedColumns(record)) {
+		changed := make(Record, len(record))
+		for column, value := range record {
+			changed[column] = value
+		}
+		changed[name] = perturb(record[name])
+		if other, err := index(uniqueBy, changed); err != nil || other != key {
+			depends = append(depends, name)
+		}
+	}
+
+	if len(depends) > 0 {
+		for _, constraint := range described.unique {
+			if subset(depends, constraint) {
+				return ""
+			}
+		}
+	}
+
+	return fmt.Sprintf("UniqueBy[%v] depends on columns %q, which no unique constraint covers", i, depends)
+}
+
+func index(uniqueBy Indexer, record Record) (key string, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("%v", recovered)
+		}
+	}()
+	return uniqueBy(record), nil
+}
+
+// A different value of the same type, so that indexers asserting types see
+// the change.
+func perturb(value any) any {
+	switch v := value.(type) {
+	case string:
+		return v + "'"
+	case bool:
+		return !v
+	case time.Time:
+		return v.Add(time.Second)
+	case nil:
+		return ""
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return reflect.ValueOf(rv.Int() + 1).Convert(rv.Type()).Interface()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return reflect.ValueOf(rv.Uint() + 1).Convert(rv.Type()).Interface()
+	case reflect.Float32, reflect.Float64:
+		return reflect.ValueOf(rv.Float() + 1).Convert(rv.Type()).Interface()
+	case reflect.String:
+		return reflect.ValueOf(rv.String() + "'").Convert(rv.Type()).Interface()
+	}
+	return nil
+}
+
+func subset(columns []string, of []string) bool {
+	for _, column := range columns {
+		if !slices.Contains(of, column) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,87 @@
+package dumbo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-faker/faker/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/thebearingedge/dumbo/internal/dumbotest"
+)
+
+func TestValidatingFactories(t *testing.T) {
+	db := dumbotest.RequireDB(t)
+
+	seeder := New(
+		Factory{
+			Table: "user",
+			NewRecord: func(Draw) Record {
+				return Record{"username": faker.Username()}
+			},
+			UniqueBy: []Indexer{
+				func(r Record) string { return r["username"].(string) },
+			},
+		},
+		Factory{
+			Table: "post",
+			NewRecord: func(Draw) Record {
+				return Record{"title": faker.Sentence()}
+			},
+			Associations: []Association{
+				{Column: "author_id", Strategy: Create()},
+			},
+		},
+	)
+
+	t.Run("passing factories that match the schema", func(t *testing.T) {
+		tx := dumbotest.RequireBegin(t, db)
+
+		seeder.Validate(t, tx)
+	})
+}
+
+func TestFactoryProblems(t *testing.T) {
+	user := &table{
+		name: "user",
+		columns: []column{
+			{name: "id", notNull: true, hasDefault: true, typ: "int4", category: "N"},
+			{name: "username", notNull: true, typ: "text", category: "S"},
+			{name: "email", notNull: true, typ: "text", category: "S"},
+			{name: "mood", typ: "mood", category: "E", labels: []string{"happy", "sad"}},
+			{name: "age", typ: "int4", category: "N"},
+			{name: "joined_at", notNull: true, typ: "timestamptz", category: "D"},
+		},
+		primaryKey: []string{"id"},
+		unique:     [][]string{{"id"}, {"username"}},
+	}
+
+	problems := validate(nil, time.Time{}, Factory{
+		Table: "user",
+		NewRecord: func(Draw) Record {
+			return Record{
+				"username":  "gopher",
+				"image_url": "https://example.com",
+				"mood":      "angry",
+				"age":       "old",
+			}
+		},
+		UniqueBy: []Indexer{
+			func(r Record) string { return r["username"].(string) },
+			func(r Record) string { return r["username"].(string) + r["image_url"].(string) },
+			func(r Record) string { return "constant" },
+			func(r Record) string { return r["email"].(string) },
+		},
+		Timestamps: []string{"joined_at"},
+	}, user)
+
+	assert.Equal(t, []string{
+		`enum column "mood" of type mood has no label "angry"`,
+		`column "age" of type int4 cannot hold string`,
+		`unknown column "image_url"`,
+		`NOT NULL column "email" has no default and is not filled`,
+		`UniqueBy[1] depends on columns ["username" "image_url"], which no unique constraint covers`,
+		`UniqueBy[2] depends on columns [], which no unique constraint covers`,
+		`UniqueBy[3] panics on a new record: interface conversion: interface {} is nil, not string`,
+	}, problems)
+}